@@ -0,0 +1,107 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const scaleKw = "scale"
+
+// Decimal is a fixed precision decimal number with the value Units * 10^-Scale, e.g. {1234, 2} for 12.34.
+type Decimal struct {
+	Units int64
+	Scale int
+}
+
+func (d Decimal) String() string {
+	s := strconv.FormatInt(d.Units, 10)
+	if d.Scale <= 0 {
+		return s
+	}
+	sign := ""
+	if d.Units < 0 {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= d.Scale {
+		s = strings.Repeat("0", d.Scale-len(s)+1) + s
+	}
+	return sign + s[:len(s)-d.Scale] + "." + s[len(s)-d.Scale:]
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseDecimal parses a decimal number like '-12.34', the scale is the number of digits after the point.
+func ParseDecimal(s string) (Decimal, error) {
+	val := s
+	if strings.HasPrefix(val, "+") || strings.HasPrefix(val, "-") {
+		val = val[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(val, ".")
+	if intPart+fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return Decimal{}, fmt.Errorf("invalid decimal '%s'", s)
+	}
+	units, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("invalid decimal '%s': %w", s, err)
+	}
+	if strings.HasPrefix(s, "-") {
+		units = -units
+	}
+	return Decimal{Units: units, Scale: len(fracPart)}, nil
+}
+
+// Rescale returns the decimal with the given scale, which must not be lower than the current scale.
+func (d Decimal) Rescale(scale int) (Decimal, error) {
+	if scale < d.Scale {
+		return Decimal{}, fmt.Errorf("'%s' has more than %d decimal places", d, scale)
+	}
+	units := d.Units
+	for i := d.Scale; i < scale; i++ {
+		if next := units * 10; next/10 == units {
+			units = next
+		} else {
+			return Decimal{}, fmt.Errorf("'%s' with %d decimal places: %w", d, scale, strconv.ErrRange)
+		}
+	}
+	return Decimal{Units: units, Scale: scale}, nil
+}
+
+var decimalParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	d, err := ParseDecimal(val)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := kwParams[scaleKw]; ok {
+		scale, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scale '%s': %w", s, err)
+		}
+		return d.Rescale(scale)
+	}
+	return d, nil
+}
@@ -0,0 +1,94 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type TestDecimalStruct struct {
+	Var1 Decimal  `env_var:"DEC_VAR_1" env_params:"scale=2"`
+	Var2 *Decimal `env_var:"DEC_VAR_1"`
+}
+
+func TestLoadDecimal(t *testing.T) {
+	testCases := []struct {
+		a    string
+		want Decimal
+		str  string
+	}{
+		{
+			a:    "12.34",
+			want: Decimal{Units: 1234, Scale: 2},
+			str:  "12.34",
+		},
+		{
+			a:    "-0.5",
+			want: Decimal{Units: -50, Scale: 2},
+			str:  "-0.50",
+		},
+		{
+			a:    "7",
+			want: Decimal{Units: 700, Scale: 2},
+			str:  "7.00",
+		},
+	}
+	for _, testCase := range testCases {
+		testStruct := TestDecimalStruct{}
+		if err := loadTestStruct(t, []TestCaseA{{a: testCase.a, env: "DEC_VAR_1"}}, &testStruct); err != nil {
+			t.Fatal(err)
+		}
+		d, _ := ParseDecimal(testCase.a)
+		testCasesB := []TestCaseB{
+			{
+				b:    testStruct.Var1,
+				want: testCase.want,
+			},
+			{
+				b:    testStruct.Var1.String(),
+				want: testCase.str,
+			},
+			{
+				b:    *testStruct.Var2,
+				want: d,
+			},
+		}
+		testValues(t, testCasesB)
+	}
+}
+
+func TestLoadDecimalError(t *testing.T) {
+	testStruct := TestDecimalStruct{}
+	err := loadTestStruct(t, []TestCaseA{{a: "1.234", env: "DEC_VAR_1"}}, &testStruct)
+	if err == nil || !strings.Contains(err.Error(), "more than 2 decimal places") {
+		t.Errorf("err = %v; want decimal places error", err)
+	}
+	for _, val := range []string{"abc", "1.2.3", "", ".", "1e5", "--1", "+-5", "-+5", "++5"} {
+		if err := loadTestStruct(t, []TestCaseA{{a: val, env: "DEC_VAR_1"}}, &testStruct); err == nil {
+			t.Errorf("missing error for '%s'", val)
+		}
+	}
+	if err := loadTestStruct(t, []TestCaseA{{a: "92233720368547758.07", env: "DEC_VAR_1"}}, &testStruct); err != nil {
+		t.Error(err)
+	}
+	if err := loadTestStruct(t, []TestCaseA{{a: "92233720368547758.1", env: "DEC_VAR_1"}}, &testStruct); !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("err = %v; want %v", err, strconv.ErrRange)
+	}
+}
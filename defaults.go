@@ -0,0 +1,84 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// deepCopy copies src to dst without sharing pointers, slices or maps, so loading can't modify the defaults.
+func deepCopy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+		ptr := reflect.New(src.Type().Elem())
+		deepCopy(ptr.Elem(), src.Elem())
+		dst.Set(ptr)
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	case reflect.Map:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			val := reflect.New(src.Type().Elem()).Elem()
+			deepCopy(val, iter.Value())
+			m.SetMapIndex(iter.Key(), val)
+		}
+		dst.Set(m)
+	case reflect.Struct:
+		dst.Set(src)
+		for _, f := range getFields(src.Type()) {
+			deepCopy(dst.Field(f.index), src.Field(f.index))
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// LoadEnvWithDefaults copies all non-zero fields of defaults to itf and loads the environment afterwards.
+// Defaults must be a struct or pointer to a struct of the same type as itf.
+func LoadEnvWithDefaults(itf interface{}, defaults interface{}, opts ...Option) error {
+	v, d := reflect.ValueOf(itf), reflect.Indirect(reflect.ValueOf(defaults))
+	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
+		v = v.Elem()
+		if d.Type() != v.Type() {
+			panic(fmt.Sprintf("'%s' provided but '%s' required", d.Type(), v.Type()))
+		}
+		for _, f := range getFields(d.Type()) {
+			if fv := d.Field(f.index); !fv.IsZero() {
+				deepCopy(v.Field(f.index), fv)
+			}
+		}
+	}
+	return LoadEnv(itf, opts...)
+}
@@ -0,0 +1,90 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"testing"
+)
+
+type TestDefaultsStruct struct {
+	Var1 []string          `env_var:"DEF_VAR_1"`
+	Var2 TestSubStruct     `env_var:"DEF_VAR_2"`
+	Var3 *TestSubStruct    `env_var:"DEF_VAR_3"`
+	Var4 map[string]string `env_var:"DEF_VAR_4"`
+	Var5 string            `env_var:"DEF_VAR_5"`
+}
+
+func newTestDefaults() TestDefaultsStruct {
+	testString := defaultString
+	return TestDefaultsStruct{
+		Var1: []string{"a", "b"},
+		Var2: TestSubStruct{Var: defaultString},
+		Var3: &TestSubStruct{VarPtr: &testString},
+		Var4: map[string]string{"a": "b"},
+	}
+}
+
+func TestLoadEnvWithDefaults(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "SUB_VAR",
+		},
+		{
+			a:   testString,
+			env: "DEF_VAR_5",
+		},
+	}
+	defaults := newTestDefaults()
+	testStruct := TestDefaultsStruct{Var5: defaultString}
+	if err := setEnv(testCasesA[1:]); err != nil {
+		panic(err)
+	}
+	defer func() {
+		if err := unsetEnv(testCasesA); err != nil {
+			panic(err)
+		}
+	}()
+	if err := LoadEnvWithDefaults(&testStruct, &defaults); err != nil {
+		t.Fatal(err)
+	}
+	want := newTestDefaults()
+	want.Var5 = testString
+	testValues(t, []TestCaseB{{b: testStruct, want: want}})
+	if err := setEnv(testCasesA[:1]); err != nil {
+		panic(err)
+	}
+	testStruct = TestDefaultsStruct{}
+	if err := LoadEnvWithDefaults(&testStruct, defaults); err != nil {
+		t.Fatal(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var2,
+			want: TestSubStruct{Var: testString, VarPtr: &testString, VarNilPtr: &testString},
+		},
+		{
+			b:    *testStruct.Var3.VarPtr,
+			want: testString,
+		},
+		{
+			b:    defaults,
+			want: newTestDefaults(),
+		},
+	}
+	testValues(t, testCasesB)
+}
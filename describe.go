@@ -0,0 +1,89 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// FieldDoc describes a tagged field for generating configuration documentation.
+type FieldDoc struct {
+	Field      string
+	EnvVar     string
+	Type       string
+	Default    string
+	Required   bool
+	RequiredIf string
+	Doc        string
+}
+
+func describe(docs []FieldDoc, v reflect.Value, path, prefix string, visited map[reflect.Type]bool) []FieldDoc {
+	for _, f := range getFields(v.Type()) {
+		fieldValue := v.Field(f.index)
+		if isConfigStruct(f.st.Type) {
+			ft := f.st.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+				if fieldValue.IsNil() {
+					fieldValue = reflect.Zero(ft)
+				} else {
+					fieldValue = fieldValue.Elem()
+				}
+			}
+			if !visited[ft] {
+				visited[ft] = true
+				docs = describe(docs, fieldValue, path+f.st.Name+".", f.subPrefix(prefix), visited)
+				delete(visited, ft)
+			}
+		} else if f.tagged {
+			required, _ := strconv.ParseBool(f.kwParams[requiredKw])
+			var dflt string
+			if !fieldValue.IsZero() {
+				dflt = formatValue(fieldValue, f.kwParams, 0)
+			}
+			docs = append(docs, FieldDoc{
+				Field:      path + f.st.Name,
+				EnvVar:     prefix + f.name,
+				Type:       f.st.Type.String(),
+				Default:    dflt,
+				Required:   required,
+				RequiredIf: f.kwParams[requiredIfKw],
+				Doc:        f.doc,
+			})
+		}
+	}
+	return docs
+}
+
+// Describe returns a FieldDoc per tagged field, including nested structs. Descriptions are taken from
+// the env_doc tag, defaults are the non-zero values of the provided struct, secrets are redacted.
+func Describe(itf interface{}) []FieldDoc {
+	v := reflect.ValueOf(itf)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.Zero(v.Type().Elem())
+		} else {
+			v = v.Elem()
+		}
+	}
+	if v.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("'%s' provided but '%s' required", v.Kind(), reflect.Struct))
+	}
+	return describe(nil, v, "", "", map[reflect.Type]bool{v.Type(): true})
+}
@@ -0,0 +1,66 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type TestDescribeSubStruct struct {
+	Host string `env_var:"HOST" env_doc:"database host" env_params:"required=true"`
+}
+
+type TestDescribeStruct struct {
+	Var1   string        `env_var:"DESC_VAR_1" env_doc:"name of the service"`
+	Var2   time.Duration `env_var:"DESC_VAR_2" env_params:"required=true"`
+	Var3   int
+	Var4   string                `env_var:"DESC_VAR_4" env_params:"required_if=MODE=prod;secret=true"`
+	Sub    TestDescribeSubStruct `env_prefix:"DB"`
+	Parent *TestDescribeStruct
+	SubPtr *TestDescribeSubStruct `env_prefix:"CACHE"`
+}
+
+func TestDescribe(t *testing.T) {
+	want := []FieldDoc{
+		{Field: "Var1", EnvVar: "DESC_VAR_1", Type: "string", Doc: "name of the service"},
+		{Field: "Var2", EnvVar: "DESC_VAR_2", Type: "time.Duration", Required: true},
+		{Field: "Var4", EnvVar: "DESC_VAR_4", Type: "string", RequiredIf: "MODE=prod"},
+		{Field: "Sub.Host", EnvVar: "DB_HOST", Type: "string", Required: true, Doc: "database host"},
+		{Field: "SubPtr.Host", EnvVar: "CACHE_HOST", Type: "string", Required: true, Doc: "database host"},
+	}
+	if docs := Describe(TestDescribeStruct{}); !reflect.DeepEqual(docs, want) {
+		t.Errorf("Describe() = %+v; want %+v", docs, want)
+	}
+	if docs := Describe((*TestDescribeStruct)(nil)); !reflect.DeepEqual(docs, want) {
+		t.Errorf("Describe() = %+v; want %+v", docs, want)
+	}
+	want[0].Default = "test"
+	want[1].Default = "5s"
+	want[2].Default = "***"
+	want[4].Default = "localhost"
+	testStruct := TestDescribeStruct{
+		Var1:   "test",
+		Var2:   5 * time.Second,
+		Var4:   "pw",
+		SubPtr: &TestDescribeSubStruct{Host: "localhost"},
+	}
+	if docs := Describe(&testStruct); !reflect.DeepEqual(docs, want) {
+		t.Errorf("Describe() = %+v; want %+v", docs, want)
+	}
+}
@@ -0,0 +1,56 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"fmt"
+	"reflect"
+)
+
+func diff(a, b reflect.Value, path string, changed []string) []string {
+	for _, f := range getFields(a.Type()) {
+		fa, fb := a.Field(f.index), b.Field(f.index)
+		name := path + f.st.Name
+		if isConfigStruct(f.st.Type) {
+			if fa.Kind() == reflect.Ptr {
+				if fa.IsNil() || fb.IsNil() {
+					if fa.IsNil() != fb.IsNil() {
+						changed = append(changed, name)
+					}
+					continue
+				}
+				fa, fb = fa.Elem(), fb.Elem()
+			}
+			changed = diff(fa, fb, name+".", changed)
+		} else if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// ChangedFields returns the paths of all exported fields that differ between two structs of the same type, e.g. 'Sub.Var'.
+func ChangedFields(before, after interface{}) ([]string, error) {
+	a, b := reflect.Indirect(reflect.ValueOf(before)), reflect.Indirect(reflect.ValueOf(after))
+	if a.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("'%s' provided but '%s' required", a.Kind(), reflect.Struct)
+	}
+	if a.Type() != b.Type() {
+		return nil, fmt.Errorf("'%s' and '%s' differ in type", a.Type(), b.Type())
+	}
+	return diff(a, b, "", nil), nil
+}
@@ -0,0 +1,48 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"testing"
+)
+
+func TestChangedFields(t *testing.T) {
+	before := newTestStruct()
+	after := newTestStruct()
+	changed, err := ChangedFields(before, &after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: changed, want: []string(nil)}})
+	after.Var17.Var = testString
+	after.Var17Ptr = &TestSubStruct{Var: defaultString, VarPtr: &testString}
+	after.Var17NilPtr = &TestSubStruct{}
+	changed, err = ChangedFields(&before, &after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: changed, want: []string{"Var17.Var", "Var17Ptr.VarPtr", "Var17NilPtr"}}})
+}
+
+func TestChangedFieldsError(t *testing.T) {
+	if _, err := ChangedFields(TestStruct{}, TestSubStruct{}); err == nil {
+		t.Error("missing error")
+	}
+	if _, err := ChangedFields(1, 1); err == nil {
+		t.Error("missing error")
+	}
+}
@@ -0,0 +1,154 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const commentPrefix = "#"
+const exportPrefix = "export "
+
+var escapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'"':  '"',
+	'\\': '\\',
+}
+
+// parseQuoted reads a quoted value that may span multiple lines. Escape sequences are only
+// interpreted in double-quoted values.
+func parseQuoted(val string, scanner *bufio.Scanner) (string, error) {
+	quote := rune(val[0])
+	var b strings.Builder
+	line := []rune(val[1:])
+	for {
+		for i := 0; i < len(line); i++ {
+			r := line[i]
+			switch {
+			case r == quote:
+				return b.String(), nil
+			case r == '\\' && quote == '"' && i+1 < len(line):
+				i++
+				if e, ok := escapes[line[i]]; ok {
+					b.WriteRune(e)
+				} else {
+					b.WriteRune(r)
+					b.WriteRune(line[i])
+				}
+			default:
+				b.WriteRune(r)
+			}
+		}
+		if !scanner.Scan() {
+			return "", fmt.Errorf("unterminated quote in value '%s'", val)
+		}
+		b.WriteRune('\n')
+		line = []rune(scanner.Text())
+	}
+}
+
+// parseDotEnv reads key value pairs, if a key occurs multiple times the last occurrence wins.
+func parseDotEnv(r io.Reader) (map[string]string, error) {
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, commentPrefix) {
+			continue
+		}
+		line = strings.TrimPrefix(line, exportPrefix)
+		kv := strings.SplitN(line, equal, 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid line %d: '%s'", n, line)
+		}
+		val := strings.TrimSpace(kv[1])
+		if val != "" && (val[0] == '"' || val[0] == '\'') {
+			var err error
+			if val, err = parseQuoted(val, scanner); err != nil {
+				return nil, fmt.Errorf("invalid line %d: %w", n, err)
+			}
+		}
+		env[strings.TrimSpace(kv[0])] = val
+	}
+	return env, scanner.Err()
+}
+
+func loadFiles(override bool, paths []string) error {
+	env := make(map[string]string)
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		fileEnv, err := parseDotEnv(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for key, val := range fileEnv {
+			env[key] = val
+		}
+	}
+	for key, val := range env {
+		if _, ok := os.LookupEnv(key); ok && !override {
+			continue
+		}
+		if err := os.Setenv(key, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileSource returns a Source for the key value pairs of a .env file.
+func fileSource(path string) (Source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	env, err := parseDotEnv(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return SourceFunc(func(name string) (string, bool) {
+		val, ok := env[name]
+		return val, ok
+	}), nil
+}
+
+// LoadFile sets env vars from a .env file. Env vars that are already set are not overwritten.
+// If a key is defined multiple times the last definition wins.
+func LoadFile(path string) error {
+	return loadFiles(false, []string{path})
+}
+
+// LoadFiles sets env vars from multiple .env files like LoadFile, keys of later files override earlier ones.
+func LoadFiles(paths ...string) error {
+	return loadFiles(false, paths)
+}
+
+// LoadFilesOverride works like LoadFiles but also overwrites env vars that are already set.
+func LoadFilesOverride(paths ...string) error {
+	return loadFiles(true, paths)
+}
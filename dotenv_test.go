@@ -0,0 +1,148 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	path := writeTestFile(t, "# comment\nFILE_VAR_1=1\n\nexport FILE_VAR_2='a b'\nFILE_VAR_3=\"c\"\nFILE_VAR_4=d\n")
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "FILE_VAR_4",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(append(testCasesA, TestCaseA{env: "FILE_VAR_1"}, TestCaseA{env: "FILE_VAR_2"}, TestCaseA{env: "FILE_VAR_3"}))
+	if err := LoadFile(path); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    os.Getenv("FILE_VAR_1"),
+			want: "1",
+		},
+		{
+			b:    os.Getenv("FILE_VAR_2"),
+			want: "a b",
+		},
+		{
+			b:    os.Getenv("FILE_VAR_3"),
+			want: "c",
+		},
+		{
+			b:    os.Getenv("FILE_VAR_4"),
+			want: testString,
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestLoadFileDuplicateKey(t *testing.T) {
+	path := writeTestFile(t, "KEY=1\nKEY=2\n")
+	defer os.Unsetenv("KEY")
+	if err := LoadFile(path); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{
+		{
+			b:    os.Getenv("KEY"),
+			want: "2",
+		},
+	})
+}
+
+func TestLoadFileInvalid(t *testing.T) {
+	path := writeTestFile(t, "KEY\n")
+	if err := LoadFile(path); err == nil {
+		t.Error("missing error")
+	}
+	if err := LoadFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("missing error")
+	}
+}
+
+func TestLoadFileQuoted(t *testing.T) {
+	path := writeTestFile(t, `FILE_VAR_1="a
+b"
+FILE_VAR_2="c\nd\t\"e\"\\"
+FILE_VAR_3='f\ng "h"'
+`)
+	defer unsetEnv([]TestCaseA{{env: "FILE_VAR_1"}, {env: "FILE_VAR_2"}, {env: "FILE_VAR_3"}})
+	if err := LoadFile(path); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    os.Getenv("FILE_VAR_1"),
+			want: "a\nb",
+		},
+		{
+			b:    os.Getenv("FILE_VAR_2"),
+			want: "c\nd\t\"e\"\\",
+		},
+		{
+			b:    os.Getenv("FILE_VAR_3"),
+			want: `f\ng "h"`,
+		},
+	}
+	testValues(t, testCasesB)
+	path = writeTestFile(t, "FILE_VAR_4=\"a\n")
+	if err := LoadFile(path); err == nil {
+		t.Error("missing error")
+	}
+}
+
+func TestLoadFiles(t *testing.T) {
+	base := writeTestFile(t, "FILE_VAR_1=base\nFILE_VAR_2=base\nFILE_VAR_3=base\n")
+	local := writeTestFile(t, "FILE_VAR_2=local\nFILE_VAR_3=local\n")
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "FILE_VAR_3",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv([]TestCaseA{{env: "FILE_VAR_1"}, {env: "FILE_VAR_2"}, {env: "FILE_VAR_3"}})
+	if err := LoadFiles(base, local); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: os.Getenv("FILE_VAR_1"), want: "base"}, {b: os.Getenv("FILE_VAR_2"), want: "local"}, {b: os.Getenv("FILE_VAR_3"), want: testString}})
+	if err := LoadFilesOverride(base, local); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: os.Getenv("FILE_VAR_1"), want: "base"}, {b: os.Getenv("FILE_VAR_2"), want: "local"}, {b: os.Getenv("FILE_VAR_3"), want: "local"}})
+	if err := LoadFiles(base, filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("missing error")
+	}
+}
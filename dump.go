@@ -0,0 +1,140 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const secretKw = "secret"
+const redacted = "***"
+
+// Redactor is implemented by types that provide their own representation for diagnostic output.
+type Redactor interface {
+	Redact() string
+}
+
+var redactorType = reflect.TypeOf((*Redactor)(nil)).Elem()
+
+// isRedactor reports whether values of t or pointers to them implement Redactor.
+func isRedactor(t reflect.Type) bool {
+	return t.Implements(redactorType) || reflect.PointerTo(t).Implements(redactorType)
+}
+
+func isSecret(kwParams map[string]string) bool {
+	s, _ := strconv.ParseBool(kwParams[secretKw])
+	return s
+}
+
+// redact masks val, revealing the given number of leading characters. Values not longer than
+// reveal are masked completely.
+func redact(val string, reveal int) string {
+	r := []rune(val)
+	if reveal <= 0 || len(r) <= reveal {
+		return redacted
+	}
+	return string(r[:reveal]) + strings.Repeat("*", len(r)-reveal)
+}
+
+// formatRaw redacts the raw value of secret fields. Raw values of Redactor types are masked
+// completely, as the type can only redact parsed values.
+func formatRaw(val string, t reflect.Type, kwParams map[string]string, reveal int) string {
+	if isRedactor(t) {
+		return redacted
+	}
+	if isSecret(kwParams) {
+		return redact(val, reveal)
+	}
+	return val
+}
+
+// formatErr omits the message of errors for secret values, as parser errors often quote the raw value.
+func formatErr(err error, t reflect.Type, kwParams map[string]string) string {
+	if isSecret(kwParams) || isRedactor(t) {
+		return redacted
+	}
+	return err.Error()
+}
+
+func formatValue(v reflect.Value, kwParams map[string]string, reveal int) string {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return "<nil>"
+	}
+	if r, ok := v.Interface().(Redactor); ok {
+		return r.Redact()
+	}
+	if v.CanAddr() {
+		if r, ok := v.Addr().Interface().(Redactor); ok {
+			return r.Redact()
+		}
+	}
+	if v.Kind() == reflect.Ptr {
+		return formatValue(v.Elem(), kwParams, reveal)
+	}
+	if isSecret(kwParams) {
+		return redact(fmt.Sprint(v.Interface()), reveal)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func isConfigStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	_, ok := builtinTypeParsers[t]
+	return t.Kind() == reflect.Struct && !ok
+}
+
+func dump(b *strings.Builder, v reflect.Value, prefix string, reveal int) {
+	for _, f := range getFields(v.Type()) {
+		fieldValue := v.Field(f.index)
+		if isConfigStruct(f.st.Type) {
+			if fieldValue.Kind() == reflect.Ptr {
+				if fieldValue.IsNil() {
+					continue
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			dump(b, fieldValue, f.subPrefix(prefix), reveal)
+		} else if f.tagged {
+			fmt.Fprintf(b, "%s=%s\n", prefix+f.name, formatValue(fieldValue, f.kwParams, reveal))
+		}
+	}
+}
+
+// Dump returns a line per tagged field with the env var name and the current value of the field.
+// Values of fields with the secret parameter or of types implementing Redactor are redacted.
+// Options other than WithSecretReveal are ignored.
+func Dump(itf interface{}, opts ...Option) string {
+	v := reflect.ValueOf(itf)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("'%s' provided but '%s' required", v.Kind(), reflect.Struct))
+	}
+	l := &loader{}
+	for _, opt := range opts {
+		opt(l)
+	}
+	var b strings.Builder
+	dump(&b, v, "", l.secretReveal)
+	return b.String()
+}
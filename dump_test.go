@@ -0,0 +1,115 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"strings"
+	"testing"
+)
+
+type TestPassword string
+
+func (p TestPassword) Redact() string {
+	return "<redacted>"
+}
+
+type TestDumpStruct struct {
+	Var1 string        `env_var:"DUMP_VAR_1"`
+	Var2 string        `env_var:"DUMP_VAR_2" env_params:"secret=true"`
+	Var3 TestPassword  `env_var:"DUMP_VAR_3"`
+	Var4 *TestPassword `env_var:"DUMP_VAR_4"`
+	Var5 *TestPassword `env_var:"DUMP_VAR_5"`
+	Var6 TestSubStruct
+}
+
+func TestDump(t *testing.T) {
+	password := TestPassword(testString)
+	testStruct := TestDumpStruct{
+		Var1: testString,
+		Var2: testString,
+		Var3: password,
+		Var4: &password,
+		Var6: TestSubStruct{Var: defaultString},
+	}
+	lines := strings.Split(Dump(&testStruct), "\n")
+	testCasesB := []TestCaseB{
+		{
+			b:    lines[0],
+			want: "DUMP_VAR_1=" + testString,
+		},
+		{
+			b:    lines[1],
+			want: "DUMP_VAR_2=***",
+		},
+		{
+			b:    lines[2],
+			want: "DUMP_VAR_3=<redacted>",
+		},
+		{
+			b:    lines[3],
+			want: "DUMP_VAR_4=<redacted>",
+		},
+		{
+			b:    lines[4],
+			want: "DUMP_VAR_5=<nil>",
+		},
+		{
+			b:    lines[5],
+			want: "SUB_VAR=" + defaultString,
+		},
+		{
+			b:    lines[6],
+			want: "SUB_VAR=<nil>",
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestDumpSecretReveal(t *testing.T) {
+	secret := "abcdefghij"
+	testStruct := struct {
+		Var1 string  `env_var:"DUMP_VAR_1" env_params:"secret=true"`
+		Var2 *string `env_var:"DUMP_VAR_2" env_params:"secret=true"`
+		Var3 string  `env_var:"DUMP_VAR_3" env_params:"secret=true"`
+		Var4 string  `env_var:"DUMP_VAR_4"`
+	}{Var1: secret, Var2: &secret, Var3: "a", Var4: secret}
+	testCasesB := []TestCaseB{
+		{
+			b:    Dump(&testStruct, WithSecretReveal(2)),
+			want: "DUMP_VAR_1=ab********\nDUMP_VAR_2=ab********\nDUMP_VAR_3=***\nDUMP_VAR_4=abcdefghij\n",
+		},
+		{
+			b:    Dump(&testStruct, WithSecretReveal(0)),
+			want: "DUMP_VAR_1=***\nDUMP_VAR_2=***\nDUMP_VAR_3=***\nDUMP_VAR_4=abcdefghij\n",
+		},
+	}
+	testValues(t, testCasesB)
+	var b strings.Builder
+	testCasesA := []TestCaseA{{a: secret, env: "DUMP_VAR_1"}}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	if err := LoadEnvVerbose(&struct {
+		Var1 string `env_var:"DUMP_VAR_1" env_params:"secret=true"`
+	}{}, &b, WithSecretReveal(3)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "set to 'abc*******'") || strings.Contains(b.String(), secret) {
+		t.Errorf("output = %s; want revealed prefix", b.String())
+	}
+}
@@ -0,0 +1,100 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type enumValues struct {
+	allowed map[interface{}]bool
+	names   []string
+}
+
+var enums = make(map[reflect.Type]*enumValues)
+var enumsMu sync.RWMutex
+
+// RegisterEnum restricts fields of type T and elements of slices, arrays and maps of type T to the given values.
+// Registering a type again replaces its values.
+func RegisterEnum[T comparable](values ...T) {
+	e := &enumValues{allowed: make(map[interface{}]bool)}
+	for _, v := range values {
+		e.allowed[v] = true
+		e.names = append(e.names, fmt.Sprint(v))
+	}
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+	enums[reflect.TypeOf((*T)(nil)).Elem()] = e
+}
+
+// checkRegisteredEnum returns an error if v has a type registered via RegisterEnum and isn't one of its values.
+func checkRegisteredEnum(v reflect.Value) error {
+	if !v.IsValid() {
+		return nil
+	}
+	enumsMu.RLock()
+	e, ok := enums[v.Type()]
+	enumsMu.RUnlock()
+	if !ok || e.allowed[v.Interface()] {
+		return nil
+	}
+	return fmt.Errorf("'%v' not in '%s'", v.Interface(), strings.Join(e.names, enumSep))
+}
+
+// checkEnumResult checks the result of a parser for field type t, including its elements.
+func checkEnumResult(itf interface{}, t reflect.Type) error {
+	enumsMu.RLock()
+	n := len(enums)
+	enumsMu.RUnlock()
+	if n == 0 {
+		return nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(itf))
+	if v.IsValid() && v.Type() != t && v.Type().ConvertibleTo(t) {
+		v = v.Convert(t)
+	}
+	return checkEnumValue(v)
+}
+
+func checkEnumValue(v reflect.Value) error {
+	if err := checkRegisteredEnum(v); err != nil {
+		return err
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			return checkEnumValue(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := checkEnumValue(v.Index(i)); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := checkEnumValue(iter.Value()); err != nil {
+				return fmt.Errorf("key '%v': %w", iter.Key().Interface(), err)
+			}
+		}
+	}
+	return nil
+}
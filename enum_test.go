@@ -0,0 +1,119 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"strings"
+	"testing"
+)
+
+type TestColor string
+
+const (
+	TestColorRed   TestColor = "red"
+	TestColorGreen TestColor = "green"
+)
+
+type TestEnumStruct struct {
+	Var1 TestColor   `env_var:"ENUM_VAR_1"`
+	Var2 *TestColor  `env_var:"ENUM_VAR_1"`
+	Var3 []TestColor `env_var:"ENUM_VAR_2" env_parser:"list"`
+	Var4 string      `env_var:"ENUM_VAR_1"`
+}
+
+func TestRegisterEnum(t *testing.T) {
+	RegisterEnum(TestColorRed, TestColorGreen)
+	testCasesA := []TestCaseA{
+		{
+			a:   "green",
+			env: "ENUM_VAR_1",
+		},
+		{
+			a:   "red,green",
+			env: "ENUM_VAR_2",
+		},
+	}
+	testStruct := TestEnumStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	if testStruct.Var2 == nil {
+		t.Fatal("Var2 not set")
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: TestColorGreen}, {b: *testStruct.Var2, want: TestColorGreen}, {b: testStruct.Var3, want: []TestColor{TestColorRed, TestColorGreen}}})
+	testCasesA[0].a = "blue"
+	err := loadTestStruct(t, testCasesA, &TestEnumStruct{})
+	if err == nil || !strings.Contains(err.Error(), "'blue' not in 'red|green'") {
+		t.Errorf("err = %v; want enum error", err)
+	}
+	testCasesA[0].a = "red"
+	testCasesA[1].a = "red,blue"
+	err = loadTestStruct(t, testCasesA, &TestEnumStruct{})
+	if err == nil || !strings.Contains(err.Error(), "element 1: 'blue' not in 'red|green'") {
+		t.Errorf("err = %v; want element enum error", err)
+	}
+	jsonStruct := struct {
+		Var1 []TestColor          `env_var:"ENUM_VAR_1"`
+		Var2 map[string]TestColor `env_var:"ENUM_VAR_2"`
+		Var3 []*TestColor         `env_var:"ENUM_VAR_3"`
+	}{}
+	testCasesA = []TestCaseA{
+		{
+			a:   `["red","green"]`,
+			env: "ENUM_VAR_1",
+		},
+		{
+			a:   `{"a":"red"}`,
+			env: "ENUM_VAR_2",
+		},
+		{
+			a:   `["green",null]`,
+			env: "ENUM_VAR_3",
+		},
+	}
+	if err = loadTestStruct(t, testCasesA, &jsonStruct); err != nil {
+		t.Fatal(err)
+	}
+	for i, val := range []string{`["blue"]`, `{"a":"blue"}`, `[null,"blue"]`} {
+		cases := append([]TestCaseA{}, testCasesA...)
+		cases[i].a = val
+		err = loadTestStruct(t, cases, &jsonStruct)
+		if err == nil || !strings.Contains(err.Error(), "'blue' not in 'red|green'") {
+			t.Errorf("err = %v; want enum error for %s", err, val)
+		}
+	}
+	groupStruct := struct {
+		Var1 TestColor   `env_group:"ENUM_JSON"`
+		Var2 []TestColor `env_group:"ENUM_JSON" env_params:"jsonpath=/list"`
+	}{}
+	testCasesA = []TestCaseA{
+		{
+			a:   `{"var1":"red","list":["green"]}`,
+			env: "ENUM_JSON",
+		},
+	}
+	if err = loadTestStruct(t, testCasesA, &groupStruct); err != nil {
+		t.Fatal(err)
+	}
+	for _, val := range []string{`{"var1":"blue"}`, `{"list":["red","blue"]}`} {
+		testCasesA[0].a = val
+		err = loadTestStruct(t, testCasesA, &groupStruct)
+		if err == nil || !strings.Contains(err.Error(), "'blue' not in 'red|green'") {
+			t.Errorf("err = %v; want enum error for %s", err, val)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import "fmt"
+
+// ErrorFormatter returns the message of a LoadError, e.g. to provide a translated message.
+type ErrorFormatter func(le *LoadError) string
+
+// LoadError is returned if the value of a field can't be loaded.
+type LoadError struct {
+	Field     string
+	EnvVar    string
+	Err       error
+	formatter ErrorFormatter
+}
+
+func (e *LoadError) Error() string {
+	if e.formatter != nil {
+		return e.formatter(e)
+	}
+	return fmt.Sprintf("loading field '%s' from env var '%s' failed: %s", e.Field, e.EnvVar, e.Err)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,166 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const jsonPathKw = "jsonpath"
+
+type fieldGroup struct {
+	fields    []string
+	envVars   []string
+	setFields []string
+	set       []string
+}
+
+func trackGroup(groups map[string]*fieldGroup, name, field, envVar string, set bool) map[string]*fieldGroup {
+	if groups == nil {
+		groups = make(map[string]*fieldGroup)
+	}
+	g, ok := groups[name]
+	if !ok {
+		g = &fieldGroup{}
+		groups[name] = g
+	}
+	g.fields = append(g.fields, field)
+	g.envVars = append(g.envVars, envVar)
+	if set {
+		g.setFields = append(g.setFields, field)
+		g.set = append(g.set, envVar)
+	}
+	return groups
+}
+
+func sortedGroups(groups map[string]*fieldGroup) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkGroups validates the group constraints after all fields have been loaded. Errors name the
+// first member of a required group or the second set member of an exclusive group.
+func (l *loader) checkGroups() error {
+	for _, name := range sortedGroups(l.requiredGroups) {
+		if g := l.requiredGroups[name]; len(g.set) == 0 {
+			return &LoadError{Field: g.fields[0], EnvVar: g.envVars[0], Err: fmt.Errorf("one of the env vars '%s' of group '%s' required but none set", strings.Join(g.envVars, "', '"), name)}
+		}
+	}
+	for _, name := range sortedGroups(l.exclusiveGroups) {
+		if g := l.exclusiveGroups[name]; len(g.set) > 1 {
+			return &LoadError{Field: g.setFields[1], EnvVar: g.set[1], Err: fmt.Errorf("env vars '%s' of group '%s' are mutually exclusive", strings.Join(g.set, "', '"), name)}
+		}
+	}
+	return nil
+}
+
+// loadGroup sets the field from the member of the JSON object stored in the group env var.
+// Members are matched by json tag or field name, case-insensitive like encoding/json.
+func (l *loader) loadGroup(fieldValue reflect.Value, f *field, prefix string) error {
+	envVar := prefix + f.group
+	val, ok := l.lookup(envVar)
+	if !ok && l.explain != nil && !l.isTagged(f) {
+		fmt.Fprintf(l.explain, "%s: group env var '%s' not set, keeping '%s'\n", f.st.Name, envVar, formatValue(fieldValue, f.kwParams, l.secretReveal))
+	}
+	if !ok || !l.isSelected(envVar) {
+		return nil
+	}
+	if ptr, ok := f.kwParams[jsonPathKw]; ok {
+		raw, err := resolvePointer(json.RawMessage(val), ptr)
+		if err != nil {
+			return &LoadError{Field: f.st.Name, EnvVar: envVar, Err: err}
+		}
+		return l.setRaw(fieldValue, f, envVar, raw, ptr)
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(val), &members); err != nil {
+		return &LoadError{Field: f.st.Name, EnvVar: envVar, Err: err}
+	}
+	raw, ok := members[f.groupKey]
+	if !ok {
+		for key, r := range members {
+			if strings.EqualFold(key, f.groupKey) {
+				raw, ok = r, true
+				break
+			}
+		}
+	}
+	if !ok {
+		if l.explain != nil && !l.isTagged(f) {
+			fmt.Fprintf(l.explain, "%s: group '%s' has no member '%s', keeping '%s'\n", f.st.Name, envVar, f.groupKey, formatValue(fieldValue, f.kwParams, l.secretReveal))
+		}
+		return nil
+	}
+	return l.setRaw(fieldValue, f, envVar, raw, f.groupKey)
+}
+
+func (l *loader) setRaw(fieldValue reflect.Value, f *field, envVar string, raw json.RawMessage, member string) error {
+	ptr := reflect.New(fieldValue.Type())
+	err := json.Unmarshal(raw, ptr.Interface())
+	if err == nil {
+		err = checkMaxItems(ptr.Interface(), f.kwParams)
+	}
+	if err == nil {
+		err = checkEnumResult(ptr.Interface(), fieldValue.Type())
+	}
+	if err != nil {
+		return &LoadError{Field: f.st.Name, EnvVar: envVar, Err: fmt.Errorf("member '%s': %w", member, err)}
+	}
+	fieldValue.Set(ptr.Elem())
+	if l.explain != nil {
+		fmt.Fprintf(l.explain, "%s: set from group '%s' member '%s' to '%s'\n", f.st.Name, envVar, member, formatValue(fieldValue, f.kwParams, l.secretReveal))
+	}
+	return nil
+}
+
+// resolvePointer returns the value referenced by a JSON pointer like /db/hosts/0 as defined in RFC 6901.
+func resolvePointer(raw json.RawMessage, ptr string) (json.RawMessage, error) {
+	if ptr == "" {
+		return raw, nil
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, fmt.Errorf("invalid json pointer '%s'", ptr)
+	}
+	for _, token := range strings.Split(ptr[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		var obj map[string]json.RawMessage
+		var arr []json.RawMessage
+		var ok bool
+		if err := json.Unmarshal(raw, &obj); err == nil {
+			raw, ok = obj[token]
+		} else if err = json.Unmarshal(raw, &arr); err == nil {
+			if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(arr) && token == strconv.Itoa(i) {
+				raw, ok = arr[i], true
+			}
+		} else if _, isSyntax := err.(*json.SyntaxError); isSyntax {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("json pointer '%s' not found", ptr)
+		}
+	}
+	return raw, nil
+}
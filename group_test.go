@@ -0,0 +1,238 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type TestGroupStruct struct {
+	Host    string   `env_group:"APP_JSON"`
+	Port    int      `env_group:"APP_JSON"`
+	Tags    []string `env_group:"APP_JSON" json:"tag_list"`
+	Timeout *int     `env_group:"APP_JSON"`
+	Var1    string   `env_var:"GROUP_VAR_1"`
+}
+
+func TestLoadGroup(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"host":"h","port":5,"tag_list":["a"]}`,
+			env: "APP_JSON",
+		},
+		{
+			a:   testString,
+			env: "GROUP_VAR_1",
+		},
+	}
+	testStruct := TestGroupStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestGroupStruct{Host: "h", Port: 5, Tags: []string{"a"}, Var1: testString}}})
+	testStruct = TestGroupStruct{Host: defaultString}
+	if err := loadTestStruct(t, nil, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestGroupStruct{Host: defaultString}}})
+}
+
+type TestGroupOverrideStruct struct {
+	Host string `env_group:"APP_JSON"`
+	Port int    `env_group:"APP_JSON" env_var:"PORT"`
+	User string `env_group:"APP_JSON" env_var:"USER_NAME"`
+}
+
+func TestLoadGroupOverride(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"host":"h","port":5,"user":"u"}`,
+			env: "APP_JSON",
+		},
+		{
+			a:   "8080",
+			env: "PORT",
+		},
+	}
+	testStruct := TestGroupOverrideStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestGroupOverrideStruct{Host: "h", Port: 8080, User: "u"}}})
+	testStruct = TestGroupOverrideStruct{}
+	if err := loadTestStruct(t, testCasesA[1:], &testStruct); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestGroupOverrideStruct{Port: 8080}}})
+}
+
+func TestLoadGroupNoOverwrite(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"host":"new","port":5,"user":"new"}`,
+			env: "APP_JSON",
+		},
+		{
+			a:   "8080",
+			env: "PORT",
+		},
+	}
+	testStruct := TestGroupOverrideStruct{Host: "old", User: "old"}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithNoOverwrite()); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestGroupOverrideStruct{Host: "old", Port: 8080, User: "old"}}})
+}
+
+type TestJsonPathStruct struct {
+	Host    string   `env_group:"APP_JSON" env_params:"jsonpath=/db/host"`
+	Port    int      `env_group:"APP_JSON" env_params:"jsonpath=/db/port"`
+	Replica string   `env_group:"APP_JSON" env_params:"jsonpath=/db/replicas/1"`
+	Path    string   `env_group:"APP_JSON" env_params:"jsonpath=/routes/a~1b"`
+	Tags    []string `env_group:"APP_JSON" json:"tags"`
+}
+
+func TestLoadGroupJsonPath(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"db":{"host":"h","port":5,"replicas":["r0","r1"]},"routes":{"a/b":"x"},"tags":["t"]}`,
+			env: "APP_JSON",
+		},
+	}
+	testStruct := TestJsonPathStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestJsonPathStruct{Host: "h", Port: 5, Replica: "r1", Path: "x", Tags: []string{"t"}}}})
+	testCasesA[0].a = `{"db":{"host":"h","port":5,"replicas":["r0"]},"routes":{}}`
+	err := loadTestStruct(t, testCasesA, &TestJsonPathStruct{})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || loadErr.Field != "Replica" || !strings.Contains(err.Error(), "json pointer '/db/replicas/1' not found") {
+		t.Errorf("err = %v; want missing path error", err)
+	}
+}
+
+func TestLoadGroupJsonPathSchema(t *testing.T) {
+	RegisterKeywordParserWithSchema("testGroupSchemaParser", func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+		return strconv.Atoi(val)
+	}, nil)
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"db":{"port":5}}`,
+			env: "APP_JSON",
+		},
+		{
+			a:   "8080",
+			env: "GROUP_PORT",
+		},
+	}
+	testStruct := struct {
+		Port int `env_group:"APP_JSON" env_var:"GROUP_PORT" env_parser:"testGroupSchemaParser" env_params:"jsonpath=/db/port"`
+	}{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Port, want: 8080}})
+}
+
+func TestLoadGroupMaxItems(t *testing.T) {
+	testStruct := struct {
+		Hosts []string `env_group:"APP_JSON" json:"hosts" env_params:"maxitems=1"`
+	}{}
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"hosts":["a"]}`,
+			env: "APP_JSON",
+		},
+	}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Hosts, want: []string{"a"}}})
+	testCasesA[0].a = `{"hosts":["a","b","c"]}`
+	err := loadTestStruct(t, testCasesA, &testStruct)
+	if err == nil || !strings.Contains(err.Error(), "3 items exceed the maximum of 1") {
+		t.Errorf("err = %v; want maxitems error", err)
+	}
+}
+
+func TestLoadGroupError(t *testing.T) {
+	var loadErr *LoadError
+	for _, val := range []string{`{"host":`, `{"port":"5"}`} {
+		testStruct := TestGroupStruct{}
+		if err := loadTestStruct(t, []TestCaseA{{a: val, env: "APP_JSON"}}, &testStruct); !errors.As(err, &loadErr) {
+			t.Errorf("err = %v; want %T", err, loadErr)
+		}
+	}
+}
+
+type TestRequiredGroupStruct struct {
+	Token    string `env_var:"RG_TOKEN" env_group_required:"creds"`
+	User     string `env_var:"RG_USER" env_group_required:"creds"`
+	Password string `env_var:"RG_PASSWORD"`
+}
+
+func TestLoadRequiredGroup(t *testing.T) {
+	for _, testCasesA := range [][]TestCaseA{
+		{{a: testString, env: "RG_TOKEN"}},
+		{{a: testString, env: "RG_USER"}, {a: testString, env: "RG_PASSWORD"}},
+		{{a: testString, env: "RG_TOKEN"}, {a: testString, env: "RG_USER"}},
+	} {
+		testStruct := TestRequiredGroupStruct{}
+		if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+			t.Error(err)
+		}
+	}
+	testStruct := TestRequiredGroupStruct{}
+	err := loadTestStruct(t, []TestCaseA{{a: testString, env: "RG_PASSWORD"}}, &testStruct)
+	var le *LoadError
+	if !errors.As(err, &le) || le.Field != "Token" || le.EnvVar != "RG_TOKEN" || le.Err.Error() != "one of the env vars 'RG_TOKEN', 'RG_USER' of group 'creds' required but none set" {
+		t.Errorf("err = %v; want group error", err)
+	}
+	if err = ValidateEnv(&TestRequiredGroupStruct{}); err == nil {
+		t.Error("missing error")
+	}
+}
+
+type TestExclusiveGroupStruct struct {
+	Token    string `env_var:"EG_TOKEN" env_group_exclusive:"auth"`
+	User     string `env_var:"EG_USER" env_group_exclusive:"auth"`
+	Password string `env_var:"EG_PASSWORD"`
+}
+
+func TestLoadExclusiveGroup(t *testing.T) {
+	for _, testCasesA := range [][]TestCaseA{
+		{{a: testString, env: "EG_TOKEN"}},
+		{{a: testString, env: "EG_USER"}, {a: testString, env: "EG_PASSWORD"}},
+		nil,
+	} {
+		testStruct := TestExclusiveGroupStruct{}
+		if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+			t.Error(err)
+		}
+	}
+	testStruct := TestExclusiveGroupStruct{}
+	err := loadTestStruct(t, []TestCaseA{{a: testString, env: "EG_TOKEN"}, {a: testString, env: "EG_USER"}}, &testStruct)
+	var le *LoadError
+	if !errors.As(err, &le) || le.Field != "User" || le.EnvVar != "EG_USER" || le.Err.Error() != "env vars 'EG_TOKEN', 'EG_USER' of group 'auth' are mutually exclusive" {
+		t.Errorf("err = %v; want group error", err)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// LazyValue can be returned by a parser to defer producing the value of a field.
+// Fields of type func() T or func() (T, error) receive a function calling the LazyValue once on first use,
+// for all other fields the LazyValue is called while loading.
+type LazyValue func() (interface{}, error)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func resolveLazy(t reflect.Type, lv LazyValue) (interface{}, error) {
+	if t.Kind() != reflect.Func {
+		return lv()
+	}
+	if t.NumIn() != 0 || t.NumOut() < 1 || t.NumOut() > 2 || (t.NumOut() == 2 && t.Out(1) != errorType) {
+		return nil, fmt.Errorf("lazy value requires func() T or func() (T, error) but got '%s'", t)
+	}
+	out := t.Out(0)
+	var once sync.Once
+	var res reflect.Value
+	var err error
+	fn := reflect.MakeFunc(t, func([]reflect.Value) []reflect.Value {
+		once.Do(func() {
+			res, err = lazyResult(out, lv)
+		})
+		if t.NumOut() == 1 {
+			if err != nil {
+				panic(err)
+			}
+			return []reflect.Value{res}
+		}
+		errValue := reflect.Zero(errorType)
+		if err != nil {
+			errValue = reflect.ValueOf(&err).Elem()
+		}
+		return []reflect.Value{res, errValue}
+	})
+	return fn.Interface(), nil
+}
+
+func lazyResult(t reflect.Type, lv LazyValue) (reflect.Value, error) {
+	itf, err := lv()
+	if err != nil {
+		return reflect.Zero(t), err
+	}
+	v := reflect.ValueOf(itf)
+	if !v.IsValid() {
+		return reflect.Zero(t), nil
+	}
+	if v.Type() != t && v.Type().ConvertibleTo(t) {
+		v = v.Convert(t)
+	}
+	if !v.Type().AssignableTo(t) {
+		return reflect.Zero(t), fmt.Errorf("lazy value of type '%s' not assignable to '%s'", v.Type(), t)
+	}
+	return v, nil
+}
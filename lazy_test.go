@@ -0,0 +1,86 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLazyValue(t *testing.T) {
+	calls := 0
+	kwParsers := map[string]Parser{
+		"fetch": func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+			return LazyValue(func() (interface{}, error) {
+				calls++
+				if val == "missing" {
+					return nil, errors.New("secret not found")
+				}
+				return "secret-" + val, nil
+			}), nil
+		},
+	}
+	testCasesA := []TestCaseA{
+		{
+			a:   "a",
+			env: "LAZY_VAR_1",
+		},
+		{
+			a:   "missing",
+			env: "LAZY_VAR_2",
+		},
+		{
+			a:   "c",
+			env: "LAZY_VAR_3",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := struct {
+		Var1 func() string          `env_var:"LAZY_VAR_1" env_parser:"fetch"`
+		Var2 func() (string, error) `env_var:"LAZY_VAR_2" env_parser:"fetch"`
+		Var3 string                 `env_var:"LAZY_VAR_3" env_parser:"fetch"`
+	}{}
+	if err := LoadEnvUserParser(&testStruct, kwParsers, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d; want 1", calls)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var3, want: "secret-c"}})
+	for i := 0; i < 2; i++ {
+		if v := testStruct.Var1(); v != "secret-a" {
+			t.Errorf("Var1() = %s; want secret-a", v)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d; want 2", calls)
+	}
+	if _, err := testStruct.Var2(); err == nil || err.Error() != "secret not found" {
+		t.Errorf("err = %v; want secret not found", err)
+	}
+	err := LoadEnvUserParser(&struct {
+		Var1 func(string) string `env_var:"LAZY_VAR_1" env_parser:"fetch"`
+	}{}, kwParsers, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "lazy value requires") {
+		t.Errorf("err = %v; want lazy value error", err)
+	}
+}
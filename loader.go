@@ -18,27 +18,72 @@ package envldr
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"os"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 const varTag = "env_var"
 const parserTag = "env_parser"
 const paramsTag = "env_params"
+const groupTag = "env_group"
+const prefixTag = "env_prefix"
+const groupRequiredTag = "env_group_required"
+const groupExclusiveTag = "env_group_exclusive"
+const docTag = "env_doc"
+const keyOrderTag = "env_key_order"
 const separator = ";"
 const equal = "="
+const requiredKw = "required"
+const requiredIfKw = "required_if"
+const recurseKw = "recurse"
+const allEnvKw = "allenv"
+const prefixKw = "prefix"
+const presenceKw = "presence"
+const useNumberKw = "usenumber"
+const deprecatedKw = "deprecated"
+const sparseKw = "sparse"
+const maxItemsKw = "maxitems"
+const indirectKw = "indirect"
+const appendKw = "append"
 
 type Parser func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error)
 
+// Decorator wraps the parser resolved for a field.
+type Decorator func(p Parser) Parser
+
+// EnvSetter is implemented by types that set themselves from the raw value of an env var.
+type EnvSetter interface {
+	SetFromEnv(val string) error
+}
+
+var envSetterType = reflect.TypeOf((*EnvSetter)(nil)).Elem()
+
+var envSetterParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	v := reflect.New(t)
+	err := v.Interface().(EnvSetter).SetFromEnv(val)
+	return v.Interface(), err
+}
+
 var bitSizeMap = map[reflect.Kind]int{
 	reflect.Int:        0,
+	reflect.Int8:       8,
 	reflect.Int16:      16,
 	reflect.Int32:      32,
 	reflect.Int64:      64,
 	reflect.Uint:       0,
+	reflect.Uint8:      8,
 	reflect.Uint16:     16,
 	reflect.Uint32:     32,
 	reflect.Uint64:     64,
@@ -84,12 +129,54 @@ var complexParser Parser = func(t reflect.Type, val string, params []string, kwP
 	}
 }
 
+func jsonIndexObject(t reflect.Type, val string) (interface{}, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(val), &obj); err != nil {
+		return nil, err
+	}
+	elems := make(map[int]json.RawMessage)
+	length := 0
+	for key, raw := range obj {
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 {
+			return nil, fmt.Errorf("invalid index '%s'", key)
+		}
+		elems[i] = raw
+		if i >= length {
+			length = i + 1
+		}
+	}
+	slice := reflect.MakeSlice(t, length, length)
+	for i, raw := range elems {
+		if err := json.Unmarshal(raw, slice.Index(i).Addr().Interface()); err != nil {
+			return nil, err
+		}
+	}
+	return slice.Interface(), nil
+}
+
 var jsonParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if sparse, _ := strconv.ParseBool(kwParams[sparseKw]); sparse && t.Kind() == reflect.Slice && strings.HasPrefix(strings.TrimSpace(val), "{") {
+		return jsonIndexObject(t, val)
+	}
 	v := reflect.New(t)
+	if useNumber, _ := strconv.ParseBool(kwParams[useNumberKw]); useNumber {
+		d := json.NewDecoder(strings.NewReader(val))
+		d.UseNumber()
+		err := d.Decode(v.Interface())
+		return v.Interface(), err
+	}
 	err := json.Unmarshal([]byte(val), v.Interface())
 	return v.Interface(), err
 }
 
+var rawMessageParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if !json.Valid([]byte(val)) {
+		return nil, fmt.Errorf("invalid json '%s'", val)
+	}
+	return json.RawMessage(val), nil
+}
+
 var parsers = map[reflect.Kind]Parser{
 	reflect.Uint:       uintParser,
 	reflect.Uint8:      uintParser,
@@ -116,111 +203,784 @@ var parsers = map[reflect.Kind]Parser{
 	reflect.Struct: jsonParser,
 }
 
-func getEnv(st reflect.StructField) (val string, parserKw string, params []string, kwParams map[string]string, ok bool) {
-	if val, ok = st.Tag.Lookup(varTag); ok && val != "" {
-		val, ok = os.LookupEnv(val)
-		if psr, k := st.Tag.Lookup(parserTag); k && psr != "" {
-			parserKw = psr
+var registry = make(map[string]Parser)
+var registryMu sync.RWMutex
+
+// RegisterKeywordParser registers a parser that can be referenced via the env_parser tag.
+func RegisterKeywordParser(name string, p Parser) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = p
+}
+
+// snapshotRegistry returns a copy of the registered parsers, so registrations during a load don't affect it.
+func snapshotRegistry() map[string]Parser {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	snapshot := make(map[string]Parser, len(registry))
+	for name, p := range registry {
+		snapshot[name] = p
+	}
+	return snapshot
+}
+
+// loaderKws are the parameters handled by the loader itself, they are accepted for every parser.
+var loaderKws = []string{requiredKw, requiredIfKw, presenceKw, deprecatedKw, maxItemsKw, secretKw, promptKw, appendKw, tmplKw, timeoutKw, jsonPathKw}
+
+// RegisterKeywordParserWithSchema registers a parser like RegisterKeywordParser but fails loading
+// fields that use keyword parameters other than the allowed ones.
+func RegisterKeywordParserWithSchema(name string, p Parser, allowed []string) {
+	known := make(map[string]bool)
+	for _, kw := range append(loaderKws, allowed...) {
+		known[kw] = true
+	}
+	RegisterKeywordParser(name, func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+		var unknown []string
+		for kw := range kwParams {
+			if !known[kw] {
+				unknown = append(unknown, kw)
+			}
 		}
-		if prms, k := st.Tag.Lookup(paramsTag); k && prms != "" {
-			parts := strings.Split(prms, separator)
-			for _, v := range parts {
-				if strings.Contains(v, equal) {
-					if kwParams == nil {
-						kwParams = make(map[string]string)
-					}
-					kp := strings.Split(v, equal)
-					kwParams[kp[0]] = kp[1]
-				} else {
-					params = append(params, v)
-				}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return nil, fmt.Errorf("parser '%s' doesn't accept parameters '%s'", name, strings.Join(unknown, "', '"))
+		}
+		return p(t, val, params, kwParams)
+	})
+}
+
+var builtinTypeParsers = map[reflect.Type]Parser{
+	reflect.TypeOf(time.Time{}):        timeParser,
+	reflect.TypeOf(time.Location{}):    locationParser,
+	reflect.TypeOf(time.Weekday(0)):    weekdayParser,
+	reflect.TypeOf(time.Month(0)):      monthParser,
+	reflect.TypeOf(time.Duration(0)):   durationParser,
+	reflect.TypeOf(regexp.Regexp{}):    regexpParser,
+	reflect.TypeOf(net.HardwareAddr{}): macParser,
+	reflect.TypeOf(net.IPNet{}):        ipNetParser,
+	reflect.TypeOf(url.Values{}):       urlValuesParser,
+	reflect.TypeOf(SemVer{}):           semVerParser,
+	reflect.TypeOf(Decimal{}):          decimalParser,
+	reflect.TypeOf(json.RawMessage{}):  rawMessageParser,
+}
+
+type loader struct {
+	kwParsers   map[string]Parser
+	typeParsers map[reflect.Type]Parser
+	kindParsers map[reflect.Kind]Parser
+	registry    map[string]Parser
+	// options
+	conflictCheck     bool
+	noOverwrite       bool
+	interpolate       bool
+	twoPass           bool
+	values            map[string]string
+	dryRun            bool
+	flagSet           *flag.FlagSet
+	flagMapper        func(string) string
+	sources           []Source
+	fieldNamePrefix   bool
+	recover           bool
+	retries           int
+	retryDelay        time.Duration
+	parseFallback     bool
+	finalizers        []func(itf interface{}) error
+	secretReveal      int
+	defaultsFile      string
+	useSnapshot       bool
+	snapshot          map[string]string
+	decorators        map[string]Decorator
+	onDeprecated      func(envVar, note string)
+	prompt            PromptFunc
+	upperCase         bool
+	explain           io.Writer
+	errorFormatter    ErrorFormatter
+	jsonNames         bool
+	requiredGroups    map[string]*fieldGroup
+	exclusiveGroups   map[string]*fieldGroup
+	upperCaseFallback bool
+	selection         map[string]bool
+	stripComments     bool
+	used              map[string]bool
+	strictPrefix      string
+	nullSentinel      string
+	prefixVar         string
+	prefix            string
+}
+
+func (l *loader) isTagged(f *field) bool {
+	return f.tagged || (l.jsonNames && f.jsonName != "")
+}
+
+// stripComment removes everything after the first '#' outside of quotes.
+func stripComment(val string) string {
+	var quote rune
+	for i, c := range val {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
 			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return strings.TrimSpace(val[:i])
+		}
+	}
+	return val
+}
+
+// subPrefix returns the prefix for the fields of a nested struct. Without env_prefix tag
+// it's derived from the field name if enabled.
+func (l *loader) subPrefix(f *field, prefix string) string {
+	if f.prefix == "" && l.fieldNamePrefix && f.namePrefix != "" {
+		return prefix + f.namePrefix + "_"
+	}
+	return f.subPrefix(prefix)
+}
+
+// isSelected reports whether the env var should be loaded, all env vars are selected if no selection is set.
+func (l *loader) isSelected(envVar string) bool {
+	return l.selection == nil || l.selection[envVar]
+}
+
+// isPrefixSelected reports whether an env var with the prefix should be loaded.
+func (l *loader) isPrefixSelected(prefix string) bool {
+	if l.selection == nil {
+		return true
+	}
+	for envVar := range l.selection {
+		if strings.HasPrefix(envVar, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *loader) envName(f *field, prefix string) string {
+	if !f.tagged {
+		return prefix + strings.ToUpper(f.jsonName)
+	}
+	if l.upperCase {
+		return prefix + strings.ToUpper(f.name)
+	}
+	return prefix + f.name
+}
+
+func (l *loader) getEnv(f *field, prefix string) (name string, val string, ok bool) {
+	if l.isTagged(f) {
+		if name, val, ok = l.lookupName(l.envName(f, prefix)); ok && l.interpolate {
+			val = os.Expand(val, l.expand)
 		}
 	}
 	return
 }
 
-func getParser(kwParsers map[string]Parser, typeParsers map[reflect.Type]Parser, kindParsers map[reflect.Kind]Parser, parserKw string, fType reflect.Type) (parser Parser, ok bool) {
-	if parserKw != "" && kwParsers != nil {
-		if parser, ok = kwParsers[parserKw]; ok {
-			return
+// lookupName falls back to the upper case form of name if enabled, the returned name is the one that was found.
+func (l *loader) lookupName(name string) (string, string, bool) {
+	if val, ok := l.lookup(name); ok || !l.upperCaseFallback {
+		return name, val, ok
+	}
+	if upper := strings.ToUpper(name); upper != name {
+		if val, ok := l.lookup(upper); ok {
+			return upper, val, true
 		}
 	}
-	if typeParsers != nil {
-		if parser, ok = typeParsers[fType]; ok {
-			return
+	return name, "", false
+}
+
+func (l *loader) lookupEnv(name string) (string, bool) {
+	if l.snapshot != nil {
+		val, ok := l.snapshot[name]
+		return val, ok
+	}
+	return os.LookupEnv(name)
+}
+
+func (l *loader) environ() map[string]string {
+	if l.snapshot != nil {
+		return l.snapshot
+	}
+	return environ()
+}
+
+func environ() map[string]string {
+	env := make(map[string]string)
+	for _, e := range os.Environ() {
+		if kv := strings.SplitN(e, equal, 2); len(kv) == 2 {
+			env[kv[0]] = kv[1]
+		}
+	}
+	return env
+}
+
+func (l *loader) lookup(name string) (string, bool) {
+	val, ok := l.lookupEnv(name)
+	if !ok && l.flagSet != nil {
+		val, ok = l.lookupFlag(name)
+	}
+	for i := 0; !ok && i < len(l.sources); i++ {
+		val, ok = l.sources[i].Lookup(name)
+	}
+	if ok && l.values != nil {
+		l.values[name] = val
+	}
+	if l.used != nil {
+		l.used[name] = true
+	}
+	return val, ok
+}
+
+func (l *loader) lookupFlag(name string) (val string, ok bool) {
+	if l.flagMapper != nil {
+		name = l.flagMapper(name)
+	}
+	l.flagSet.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			val, ok = f.Value.String(), true
+		}
+	})
+	return
+}
+
+func (l *loader) expand(name string) string {
+	return l.values[name]
+}
+
+func (l *loader) collect(t reflect.Type, prefix string, visited map[reflect.Type]bool) {
+	for _, f := range getFields(t) {
+		if l.isTagged(&f) {
+			l.lookupName(l.envName(&f, prefix))
+		}
+		ft := f.st.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && !visited[ft] {
+			visited[ft] = true
+			l.collect(ft, l.subPrefix(&f, prefix), visited)
+			delete(visited, ft)
+		}
+	}
+}
+
+// hasEnvVal reports whether an env var is set for a field of t or of its nested structs.
+func (l *loader) hasEnvVal(t reflect.Type, prefix string) bool {
+	return l.hasNestedEnvVal(t, prefix, map[reflect.Type]bool{t: true})
+}
+
+func (l *loader) hasNestedEnvVal(t reflect.Type, prefix string, visited map[reflect.Type]bool) bool {
+	fields := getFields(t)
+	for x := range fields {
+		f := &fields[x]
+		if name, _, k := l.getEnv(f, prefix); k && l.isSelected(name) {
+			return true
+		}
+		ft := f.st.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if isConfigStruct(ft) && !visited[ft] {
+			visited[ft] = true
+			found := l.hasNestedEnvVal(ft, l.subPrefix(f, prefix), visited)
+			delete(visited, ft)
+			if found {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (l *loader) isRequired(kwParams map[string]string) (bool, error) {
+	if v, ok := kwParams[requiredKw]; ok {
+		return strconv.ParseBool(v)
+	}
+	if v, ok := kwParams[requiredIfKw]; ok {
+		cond := strings.SplitN(v, equal, 2)
+		if len(cond) != 2 {
+			return false, fmt.Errorf("invalid condition '%s'", v)
 		}
+		val, k := l.lookup(cond[0])
+		return k && val == cond[1], nil
 	}
-	if kindParsers != nil {
-		if parser, ok = kindParsers[fType.Kind()]; ok {
+	return false, nil
+}
+
+// resolveIndirect returns the name and value of the env var named by the value of envVar.
+func (l *loader) resolveIndirect(f *field, envVar, name string) (string, string, error) {
+	if name == envVar {
+		return "", "", &LoadError{Field: f.st.Name, EnvVar: envVar, Err: fmt.Errorf("env var '%s' references itself", envVar)}
+	}
+	val, ok := l.lookup(name)
+	if !ok {
+		return "", "", &LoadError{Field: f.st.Name, EnvVar: envVar, Err: fmt.Errorf("env var '%s' referenced by '%s' not set", name, envVar)}
+	}
+	return name, val, nil
+}
+
+func (l *loader) checkRequired(f *field, prefix string) error {
+	if l.isTagged(f) {
+		envVar := l.envName(f, prefix)
+		if required, err := l.isRequired(f.kwParams); err != nil {
+			return &LoadError{Field: f.st.Name, EnvVar: envVar, Err: err}
+		} else if required {
+			return &LoadError{Field: f.st.Name, EnvVar: envVar, Err: fmt.Errorf("env var '%s' required but not set", envVar)}
+		}
+	}
+	return nil
+}
+
+func (l *loader) getParser(parserKw string, fType reflect.Type) (parser Parser, desc string, ok bool) {
+	if parserKw != "" {
+		if l.kwParsers != nil {
+			if parser, ok = l.kwParsers[parserKw]; ok {
+				return parser, fmt.Sprintf("keyword parser '%s'", parserKw), ok
+			}
+		}
+		if parser, ok = l.registry[parserKw]; ok {
+			return parser, fmt.Sprintf("registered keyword parser '%s'", parserKw), ok
+		}
+		if parser, ok = builtinKwParsers[parserKw]; ok {
+			return parser, fmt.Sprintf("built-in keyword parser '%s'", parserKw), ok
+		}
+		if d, k := l.decorators[parserKw]; k {
+			if parser, desc, ok = l.getParser("", fType); ok {
+				parser = d(parser)
+				desc = fmt.Sprintf("%s decorated by '%s'", desc, parserKw)
+			}
 			return
 		}
 	}
+	if reflect.PointerTo(fType).Implements(envSetterType) {
+		return envSetterParser, "EnvSetter", true
+	}
+	if l.typeParsers != nil {
+		if parser, ok = l.typeParsers[fType]; ok {
+			return parser, fmt.Sprintf("type parser '%s'", fType), ok
+		}
+	}
+	if l.kindParsers != nil {
+		if parser, ok = l.kindParsers[fType.Kind()]; ok {
+			return parser, fmt.Sprintf("kind parser '%s'", fType.Kind()), ok
+		}
+	}
+	if parser, ok = builtinTypeParsers[fType]; ok {
+		return parser, fmt.Sprintf("built-in type parser '%s'", fType), ok
+	}
 	if parser, ok = parsers[fType.Kind()]; ok {
-		return
+		return parser, fmt.Sprintf("default parser '%s'", fType.Kind()), ok
 	}
 	return
 }
 
-func loadEnv(v reflect.Value, kwParsers map[string]Parser, typeParsers map[reflect.Type]Parser, kindParsers map[reflect.Kind]Parser) error {
-	for i := 0; i < v.Type().NumField(); i++ {
-		structField := v.Type().Field(i)
-		if structField.PkgPath == "" {
-			fieldValue := v.Field(i)
-			isNilPtr := false
-			if fieldValue.Kind() == reflect.Ptr {
-				if fieldValue.IsNil() {
-					isNilPtr = true
-				} else {
-					fieldValue = fieldValue.Elem()
+// parse calls the parser and retries failed attempts if enabled.
+func (l *loader) parse(p Parser, st reflect.StructField, envVar string, t reflect.Type, val string, params []string, kwParams map[string]string) (itf interface{}, err error) {
+	itf, err = l.parseOnce(p, st, envVar, t, val, params, kwParams)
+	for i := 0; err != nil && i < l.retries; i++ {
+		time.Sleep(l.retryDelay)
+		itf, err = l.parseOnce(p, st, envVar, t, val, params, kwParams)
+	}
+	return
+}
+
+func (l *loader) parseOnce(p Parser, st reflect.StructField, envVar string, t reflect.Type, val string, params []string, kwParams map[string]string) (itf interface{}, err error) {
+	if l.recover {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &LoadError{Field: st.Name, EnvVar: envVar, Err: fmt.Errorf("parser panicked: %v", r)}
+			}
+		}()
+	}
+	itf, err = l.callParser(p, t, val, params, kwParams)
+	if lv, ok := itf.(LazyValue); ok && err == nil {
+		itf, err = resolveLazy(t, lv)
+	}
+	if err == nil {
+		err = checkMaxItems(itf, kwParams)
+	} else if _, ok := bitSizeMap[t.Kind()]; ok && errors.Is(err, strconv.ErrRange) {
+		err = fmt.Errorf("value %s overflows %s: %w", val, t, strconv.ErrRange)
+	}
+	if err == nil {
+		err = checkEnumResult(itf, t)
+	}
+	if _, ok := err.(*LoadError); err != nil && !ok {
+		err = &LoadError{Field: st.Name, EnvVar: envVar, Err: err}
+	}
+	return
+}
+
+func isAppend(t reflect.Type, kwParams map[string]string) bool {
+	a, _ := strconv.ParseBool(kwParams[appendKw])
+	return a && t.Kind() == reflect.Slice
+}
+
+// appendSlice returns a new slice with the elements of both slices, so the backing array of a is never shared.
+func appendSlice(a, b reflect.Value) reflect.Value {
+	if b.Type() != a.Type() {
+		b = b.Convert(a.Type())
+	}
+	s := reflect.MakeSlice(a.Type(), 0, a.Len()+b.Len())
+	return reflect.AppendSlice(reflect.AppendSlice(s, a), b)
+}
+
+func checkMaxItems(itf interface{}, kwParams map[string]string) error {
+	m, ok := kwParams[maxItemsKw]
+	if !ok {
+		return nil
+	}
+	maxItems, err := strconv.Atoi(m)
+	if err != nil {
+		return fmt.Errorf("invalid %s '%s': %w", maxItemsKw, m, err)
+	}
+	if v := reflect.Indirect(reflect.ValueOf(itf)); v.Kind() == reflect.Slice && v.Len() > maxItems {
+		return fmt.Errorf("%d items exceed the maximum of %d", v.Len(), maxItems)
+	}
+	return nil
+}
+
+func (l *loader) loadIndexed(fieldValue reflect.Value, prefix string) error {
+	if fieldValue.Kind() != reflect.Slice {
+		return fmt.Errorf("'%s' provided but '%s' required", fieldValue.Kind(), reflect.Slice)
+	}
+	elemType := fieldValue.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("'%s' provided but '%s' required", structType.Kind(), reflect.Struct)
+	}
+	slice := reflect.MakeSlice(fieldValue.Type(), 0, 0)
+	if l.selection != nil {
+		// keep the current elements on selective reloads, only selected env vars are loaded into them
+		slice = reflect.AppendSlice(slice, fieldValue)
+	}
+	for i := 0; ; i++ {
+		elemPrefix := prefix + strconv.Itoa(i) + "_"
+		if !l.hasEnvVal(structType, elemPrefix) {
+			if i < slice.Len() {
+				continue
+			}
+			break
+		}
+		elem := reflect.New(structType)
+		if i < slice.Len() {
+			if cur := slice.Index(i); elemType.Kind() != reflect.Ptr {
+				elem.Elem().Set(cur)
+			} else if !cur.IsNil() {
+				elem.Elem().Set(cur.Elem())
+			}
+		}
+		if err := l.loadEnv(elem.Elem(), elemPrefix); err != nil {
+			return err
+		}
+		if elemType.Kind() != reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if i < slice.Len() {
+			slice.Index(i).Set(elem)
+		} else {
+			slice = reflect.Append(slice, elem)
+		}
+	}
+	if slice.Len() > 0 {
+		fieldValue.Set(slice)
+	}
+	return nil
+}
+
+func checkConflicts(t reflect.Type) error {
+	fields := make(map[string]reflect.StructField)
+	for _, f := range getFields(t) {
+		if f.tagged {
+			if st, k := fields[f.name]; k {
+				if baseKind(st.Type) != baseKind(f.st.Type) {
+					return &LoadError{Field: f.st.Name, EnvVar: f.name, Err: fmt.Errorf("env var '%s' used by '%s' (%s) and '%s' (%s)", f.name, st.Name, st.Type, f.st.Name, f.st.Type)}
 				}
+			} else {
+				fields[f.name] = f.st
 			}
-			if envVal, parserKw, params, kwParams, ok := getEnv(structField); ok {
-				fieldType := fieldValue.Type()
-				if isNilPtr {
-					fieldType = fieldValue.Type().Elem()
-					fieldValue.Set(reflect.New(fieldType))
-					fieldValue = fieldValue.Elem()
+		}
+	}
+	return nil
+}
+
+func baseKind(t reflect.Type) reflect.Kind {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem().Kind()
+	}
+	return t.Kind()
+}
+
+func (l *loader) loadAllEnv(fieldValue reflect.Value, f *field) error {
+	if fieldValue.Kind() != reflect.Map || fieldValue.Type().Key().Kind() != reflect.String || fieldValue.Type().Elem().Kind() != reflect.String {
+		return fmt.Errorf("'%s' provided but 'map[string]string' required", fieldValue.Type())
+	}
+	if (l.noOverwrite && !fieldValue.IsZero()) || !l.isPrefixSelected(f.kwParams[prefixKw]) {
+		return nil
+	}
+	m := reflect.MakeMap(fieldValue.Type())
+	for key, val := range l.environ() {
+		if strings.HasPrefix(key, f.kwParams[prefixKw]) {
+			if l.used != nil {
+				l.used[key] = true
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(fieldValue.Type().Key()), reflect.ValueOf(val).Convert(fieldValue.Type().Elem()))
+		}
+	}
+	fieldValue.Set(m)
+	if l.explain != nil {
+		fmt.Fprintf(l.explain, "%s: set from %d env vars with prefix '%s'\n", f.st.Name, m.Len(), f.kwParams[prefixKw])
+	}
+	return nil
+}
+
+func loadPresence(fieldValue reflect.Value, ok bool) error {
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+		}
+		fieldValue = fieldValue.Elem()
+	}
+	if fieldValue.Kind() != reflect.Bool {
+		return fmt.Errorf("'%s' provided but '%s' required", fieldValue.Kind(), reflect.Bool)
+	}
+	fieldValue.SetBool(ok)
+	return nil
+}
+
+func (l *loader) loadEnv(v reflect.Value, prefix string) error {
+	if l.dryRun {
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+		v = cp
+	}
+	if l.conflictCheck {
+		if err := checkConflicts(v.Type()); err != nil {
+			return err
+		}
+	}
+	fields := getFields(v.Type())
+	for x := range fields {
+		f := &fields[x]
+		structField := f.st
+		fieldValue := v.Field(f.index)
+		keep := l.noOverwrite && !fieldValue.IsZero()
+		if f.group != "" {
+			if !keep {
+				if err := l.loadGroup(fieldValue, f, prefix); err != nil {
+					return err
 				}
-				if p, k := getParser(kwParsers, typeParsers, kindParsers, parserKw, fieldType); k {
-					if itf, err := p(fieldType, envVal, params, kwParams); err != nil {
+			}
+			if !f.tagged {
+				continue
+			}
+		}
+		if f.parserKw == allEnvKw {
+			if err := l.loadAllEnv(fieldValue, f); err != nil {
+				return err
+			}
+			continue
+		}
+		if l.isTagged(f) {
+			if f.parserKw == recurseKw {
+				if !keep {
+					if err := l.loadIndexed(fieldValue, l.envName(f, prefix)+"_"); err != nil {
 						return err
-					} else {
-						itfValue := reflect.Indirect(reflect.ValueOf(itf))
-						fieldValue.Set(itfValue)
+					}
+					if l.explain != nil {
+						fmt.Fprintf(l.explain, "%s: set from %d elements with prefix '%s'\n", structField.Name, fieldValue.Len(), l.envName(f, prefix)+"_")
 					}
 				}
-
+				continue
+			}
+		}
+		isNilPtr := false
+		if fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				isNilPtr = true
 			} else {
-				if isNilPtr && fieldValue.Type().Elem().Kind() == reflect.Struct {
-					var hasEnvVal bool
-					for x := 0; x < fieldValue.Type().Elem().NumField(); x++ {
-						st := fieldValue.Type().Elem().Field(x)
-						if _, _, _, _, k := getEnv(st); k {
-							hasEnvVal = true
-							break
-						}
+				if l.dryRun {
+					ptr := reflect.New(fieldValue.Type().Elem())
+					ptr.Elem().Set(fieldValue.Elem())
+					fieldValue.Set(ptr)
+				}
+				fieldValue = fieldValue.Elem()
+			}
+		}
+		envVar, envVal, ok := l.getEnv(f, prefix)
+		if p, _ := strconv.ParseBool(f.kwParams[presenceKw]); p && l.isTagged(f) {
+			if l.isSelected(envVar) && !keep {
+				if err := loadPresence(fieldValue, ok); err != nil {
+					return err
+				}
+				if l.explain != nil {
+					fmt.Fprintf(l.explain, "%s: presence of env var '%s' is '%t'\n", structField.Name, envVar, ok)
+				}
+			}
+			continue
+		}
+		if text, k := f.kwParams[tmplKw]; k && !ok {
+			var err error
+			if envVal, err = l.renderTemplate(f, envVar, text); err != nil {
+				return err
+			}
+			ok = true
+		}
+		selected := l.isSelected(l.envName(f, prefix)) || (ok && l.isSelected(envVar))
+		if !ok && selected && !keep {
+			var err error
+			if envVar, envVal, ok, err = l.promptEnv(f, prefix); err != nil {
+				return err
+			}
+		}
+		if ok && selected && f.parserKw == indirectKw {
+			var err error
+			if envVar, envVal, err = l.resolveIndirect(f, envVar, envVal); err != nil {
+				return err
+			}
+		}
+		if f.requiredGroup != "" && l.isTagged(f) {
+			l.requiredGroups = trackGroup(l.requiredGroups, f.requiredGroup, f.st.Name, l.envName(f, prefix), ok)
+		}
+		if f.exclusiveGroup != "" && l.isTagged(f) {
+			l.exclusiveGroups = trackGroup(l.exclusiveGroups, f.exclusiveGroup, f.st.Name, l.envName(f, prefix), ok)
+		}
+		if ok && !selected {
+			continue
+		}
+		if ok {
+			if note, k := f.kwParams[deprecatedKw]; k && l.onDeprecated != nil {
+				l.onDeprecated(envVar, note)
+			}
+			if keep {
+				continue
+			}
+			if l.nullSentinel != "" && envVal == l.nullSentinel {
+				v.Field(f.index).Set(reflect.Zero(structField.Type))
+				continue
+			}
+			fieldType := fieldValue.Type()
+			if isNilPtr {
+				fieldType = fieldValue.Type().Elem()
+				fieldValue.Set(reflect.New(fieldType))
+				fieldValue = fieldValue.Elem()
+			}
+			if _, numeric := bitSizeMap[fieldType.Kind()]; l.stripComments && (numeric || fieldType.Kind() == reflect.Bool) {
+				envVal = stripComment(envVal)
+			}
+			p, desc, k := l.getParser(f.parserKw, fieldType)
+			if f.parserKw == queryKw {
+				p, desc, k = l.queryParser(fieldValue, envVar), "query parser", true
+			}
+			if k {
+				itf, err := l.parse(p, structField, envVar, fieldType, envVal, f.params, f.kwParams)
+				if err != nil {
+					if !l.parseFallback {
+						return err
 					}
-					if hasEnvVal {
-						fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
-						fieldValue = fieldValue.Elem()
+					if isNilPtr {
+						v.Field(f.index).Set(reflect.Zero(structField.Type))
+					}
+					if l.explain != nil {
+						fmt.Fprintf(l.explain, "%s: env var '%s' set but parsing failed, keeping '%s': %s\n", structField.Name, envVar, formatValue(v.Field(f.index), f.kwParams, l.secretReveal), formatErr(err, fieldType, f.kwParams))
+					}
+					continue
+				}
+				if isAppend(fieldType, f.kwParams) {
+					fieldValue.Set(appendSlice(fieldValue, reflect.Indirect(reflect.ValueOf(itf))))
+				} else if ptrValue := reflect.ValueOf(itf); ptrValue.Kind() == reflect.Ptr && ptrValue.Type() == v.Field(f.index).Type() {
+					v.Field(f.index).Set(ptrValue)
+				} else if fieldType.Kind() == reflect.Interface && ptrValue.IsValid() && ptrValue.Type().Implements(fieldType) {
+					fieldValue.Set(ptrValue)
+				} else {
+					itfValue := reflect.Indirect(reflect.ValueOf(itf))
+					if itfValue.Type() != fieldType && itfValue.Type().ConvertibleTo(fieldType) {
+						itfValue = itfValue.Convert(fieldType)
 					}
+					fieldValue.Set(itfValue)
 				}
-				if fieldValue.Kind() == reflect.Struct {
-					if err := loadEnv(fieldValue, kwParsers, typeParsers, kindParsers); err != nil {
+				if f.keyOrder != "" {
+					if err := setKeyOrder(v, f, envVar, envVal); err != nil {
 						return err
 					}
 				}
+				if l.explain != nil {
+					fmt.Fprintf(l.explain, "%s: env var '%s' set to '%s', parsed by %s to '%s'\n", structField.Name, envVar, formatRaw(envVal, fieldType, f.kwParams, l.secretReveal), desc, formatValue(v.Field(f.index), f.kwParams, l.secretReveal))
+				}
+			} else if l.explain != nil {
+				fmt.Fprintf(l.explain, "%s: env var '%s' set but no parser for '%s'\n", structField.Name, envVar, fieldType)
+			}
+
+		} else {
+			if selected {
+				if err := l.checkRequired(f, prefix); err != nil {
+					return err
+				}
+			}
+			if l.isTagged(f) && l.explain != nil {
+				fmt.Fprintf(l.explain, "%s: env var '%s' not set, keeping '%s'\n", structField.Name, l.envName(f, prefix), formatValue(v.Field(f.index), f.kwParams, l.secretReveal))
+			}
+			if isNilPtr && fieldValue.Type().Elem().Kind() == reflect.Struct && f.parserKw != queryKw {
+				if l.hasEnvVal(fieldValue.Type().Elem(), l.subPrefix(f, prefix)) {
+					fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+					fieldValue = fieldValue.Elem()
+				}
+			}
+			if fieldValue.Kind() == reflect.Struct && f.parserKw != queryKw {
+				if err := l.loadEnv(fieldValue, l.subPrefix(f, prefix)); err != nil {
+					return err
+				}
 			}
 		}
 	}
 	return nil
 }
 
-func LoadEnvUserParser(itf interface{}, keywordParsers map[string]Parser, typeParsers map[reflect.Type]Parser, kindParsers map[reflect.Kind]Parser) error {
+func load(itf interface{}, l *loader, opts []Option) error {
 	if v := reflect.ValueOf(itf); v.Kind() == reflect.Ptr {
 		if v = v.Elem(); v.Kind() == reflect.Struct {
-			return loadEnv(v, keywordParsers, typeParsers, kindParsers)
+			for _, opt := range opts {
+				opt(l)
+			}
+			l.registry = snapshotRegistry()
+			if l.defaultsFile != "" {
+				src, err := fileSource(l.defaultsFile)
+				if err != nil {
+					return err
+				}
+				l.sources = append(l.sources, src)
+			}
+			if l.useSnapshot {
+				l.snapshot = environ()
+			}
+			if l.prefixVar != "" {
+				l.prefix, _ = l.lookupEnv(l.prefixVar)
+			}
+			if l.interpolate {
+				l.values = make(map[string]string)
+				if l.twoPass {
+					l.collect(v.Type(), l.prefix, make(map[reflect.Type]bool))
+				}
+			}
+			err := l.loadEnv(v, l.prefix)
+			if err == nil {
+				err = l.checkGroups()
+			}
+			if err == nil && l.strictPrefix != "" {
+				if unused := l.unused(l.strictPrefix); len(unused) > 0 {
+					err = fmt.Errorf("unknown env vars with prefix '%s': %s", l.strictPrefix, strings.Join(unused, ", "))
+				}
+			}
+			for i := 0; err == nil && !l.dryRun && i < len(l.finalizers); i++ {
+				err = l.finalizers[i](itf)
+			}
+			var le *LoadError
+			if l.errorFormatter != nil && errors.As(err, &le) {
+				le.formatter = l.errorFormatter
+			}
+			return err
 		} else {
 			panic(fmt.Sprintf("'%s' provided but '%s' required", v.Kind(), reflect.Struct))
 		}
@@ -229,6 +989,35 @@ func LoadEnvUserParser(itf interface{}, keywordParsers map[string]Parser, typePa
 	}
 }
 
-func LoadEnv(itf interface{}) error {
-	return LoadEnvUserParser(itf, nil, nil, nil)
+func LoadEnvUserParser(itf interface{}, keywordParsers map[string]Parser, typeParsers map[reflect.Type]Parser, kindParsers map[reflect.Kind]Parser, opts ...Option) error {
+	l := &loader{
+		kwParsers:   keywordParsers,
+		typeParsers: typeParsers,
+		kindParsers: kindParsers,
+	}
+	return load(itf, l, opts)
+}
+
+func LoadEnv(itf interface{}, opts ...Option) error {
+	return LoadEnvUserParser(itf, nil, nil, nil, opts...)
+}
+
+// LoadEnvVerbose loads like LoadEnv and writes a line per tagged field to w, describing the selected parser,
+// the raw and the resulting value. Values of fields with the secret parameter or of types implementing Redactor are redacted.
+func LoadEnvVerbose(itf interface{}, w io.Writer, opts ...Option) error {
+	return load(itf, &loader{explain: w}, opts)
+}
+
+// ReloadChanged loads only fields whose env var is contained in changed and leaves all other fields untouched.
+func ReloadChanged(itf interface{}, changed []string, opts ...Option) error {
+	l := &loader{selection: make(map[string]bool)}
+	for _, envVar := range changed {
+		l.selection[envVar] = true
+	}
+	return load(itf, l, opts)
+}
+
+// ValidateEnv parses the environment like LoadEnv but leaves the provided struct untouched.
+func ValidateEnv(itf interface{}, opts ...Option) error {
+	return load(itf, &loader{dryRun: true}, opts)
 }
@@ -18,9 +18,13 @@ package envldr
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -51,6 +55,18 @@ func unsetEnv(testCases []TestCaseA) (err error) {
 	return
 }
 
+func loadTestStruct(t *testing.T, testCasesA []TestCaseA, itf interface{}, opts ...Option) error {
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer func() {
+		if err := unsetEnv(testCasesA); err != nil {
+			panic(err)
+		}
+	}()
+	return LoadEnv(itf, opts...)
+}
+
 func testValues(t *testing.T, testCases []TestCaseB) {
 	for _, testCase := range testCases {
 		if !reflect.DeepEqual(testCase.b, testCase.want) {
@@ -745,3 +761,1308 @@ func TestKeywordParser(t *testing.T) {
 	}
 	testValues(t, testCasesB)
 }
+
+type TestRequiredStruct struct {
+	Var1 string `env_var:"REQ_VAR_1" env_params:"required_if=MODE=prod"`
+	Var2 string `env_var:"REQ_VAR_2" env_params:"required=true"`
+}
+
+func TestRequired(t *testing.T) {
+	testStruct := TestRequiredStruct{}
+	if err := LoadEnv(&testStruct); err == nil {
+		t.Error("missing error")
+	}
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "REQ_VAR_2",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRequiredIf(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "REQ_VAR_2",
+		},
+		{
+			a:   "prod",
+			env: "MODE",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestRequiredStruct{}
+	if err := LoadEnv(&testStruct); err == nil {
+		t.Error("missing error")
+	}
+	if err := os.Setenv("MODE", "dev"); err != nil {
+		panic(err)
+	}
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Error(err)
+	}
+	if err := setEnv([]TestCaseA{{a: "prod", env: "MODE"}, {a: testString, env: "REQ_VAR_1"}}); err != nil {
+		panic(err)
+	}
+	defer os.Unsetenv("REQ_VAR_1")
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: testString,
+		},
+	})
+}
+
+type TestRegistryStruct struct {
+	Var1 int64 `env_var:"REG_VAR_1" env_parser:"testGlobalParser"`
+}
+
+func TestRegisterKeywordParser(t *testing.T) {
+	RegisterKeywordParser("testGlobalParser", testKeywordParser)
+	testCasesA := []TestCaseA{
+		{
+			a:   strconv.FormatInt(testInt64, 10),
+			env: "REG_VAR_1",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestRegistryStruct{}
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: testInt64 + 1,
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+type TestSchemaStruct struct {
+	Var1 int64 `env_var:"REG_VAR_1" env_parser:"testSchemaParser" env_params:"step=2;required=true"`
+	Var2 int64 `env_var:"REG_VAR_1" env_parser:"testSchemaParser" env_params:"stpe=2;size=1"`
+}
+
+func TestRegisterKeywordParserWithSchema(t *testing.T) {
+	RegisterKeywordParserWithSchema("testSchemaParser", func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+		i, _ := strconv.ParseInt(val, 10, 64)
+		step, _ := strconv.ParseInt(kwParams["step"], 10, 64)
+		return i + step, nil
+	}, []string{"step"})
+	testCasesA := []TestCaseA{
+		{
+			a:   "1",
+			env: "REG_VAR_1",
+		},
+	}
+	testStruct := struct {
+		Var1 int64 `env_var:"REG_VAR_1" env_parser:"testSchemaParser" env_params:"step=2;required=true"`
+	}{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: int64(3)}})
+	err := loadTestStruct(t, testCasesA, &TestSchemaStruct{})
+	if err == nil || !strings.Contains(err.Error(), "parser 'testSchemaParser' doesn't accept parameters 'size', 'stpe'") {
+		t.Errorf("err = %v; want unknown parameter error", err)
+	}
+}
+
+func TestRegistrySnapshot(t *testing.T) {
+	RegisterKeywordParser("testSnapshotParser", func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+		RegisterKeywordParser("testLateParser", testKeywordParser)
+		return strconv.ParseInt(val, 10, 64)
+	})
+	testCasesA := []TestCaseA{
+		{
+			a:   "1",
+			env: "REG_VAR_1",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	type testStruct struct {
+		Var1 int64 `env_var:"REG_VAR_1" env_parser:"testSnapshotParser"`
+		Var2 int64 `env_var:"REG_VAR_1" env_parser:"testLateParser"`
+	}
+	s := testStruct{}
+	if err := LoadEnv(&s); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: s, want: testStruct{Var1: 1, Var2: 1}}})
+	if err := LoadEnv(&s); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: s, want: testStruct{Var1: 1, Var2: 2}}})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				RegisterKeywordParser(fmt.Sprintf("testConcurrentParser%d", i), testKeywordParser)
+				RegisterKeywordParser("testLateParser", testKeywordParser)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				s := testStruct{}
+				if err := LoadEnv(&s); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+type TestIndexedStruct struct {
+	Var1 []TestSubStruct  `env_var:"ITEM" env_parser:"recurse"`
+	Var2 []*TestSubStruct `env_var:"ITEM" env_parser:"recurse"`
+}
+
+func TestLoadIndexedStructSlice(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString + "0",
+			env: "ITEM_0_SUB_VAR",
+		},
+		{
+			a:   testString + "1",
+			env: "ITEM_1_SUB_VAR",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestIndexedStruct{}
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Error(err)
+	}
+	if len(testStruct.Var1) != 2 || len(testStruct.Var2) != 2 {
+		t.Fatalf("len = %d, %d; want 2", len(testStruct.Var1), len(testStruct.Var2))
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1[0].Var,
+			want: testString + "0",
+		},
+		{
+			b:    *testStruct.Var1[1].VarNilPtr,
+			want: testString + "1",
+		},
+		{
+			b:    testStruct.Var2[0].Var,
+			want: testString + "0",
+		},
+		{
+			b:    testStruct.Var2[1].Var,
+			want: testString + "1",
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+type TestIndexedItem struct {
+	A string `env_var:"A"`
+	B string `env_var:"B"`
+}
+
+type TestIndexedReloadStruct struct {
+	Var1 []TestIndexedItem  `env_var:"ITEM" env_parser:"recurse"`
+	Var2 []*TestIndexedItem `env_var:"ITEM" env_parser:"recurse"`
+}
+
+func TestReloadChangedIndexed(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "a0",
+			env: "ITEM_0_A",
+		},
+		{
+			a:   "b0",
+			env: "ITEM_0_B",
+		},
+		{
+			a:   "a1",
+			env: "ITEM_1_A",
+		},
+		{
+			a:   "b1",
+			env: "ITEM_1_B",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestIndexedReloadStruct{}
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Fatal(err)
+	}
+	old := testStruct.Var2[0]
+	testCasesA = []TestCaseA{
+		{
+			a:   "a0new",
+			env: "ITEM_0_A",
+		},
+		{
+			a:   "a2",
+			env: "ITEM_2_A",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	if err := ReloadChanged(&testStruct, []string{"ITEM_0_A"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []TestIndexedItem{{A: "a0new", B: "b0"}, {A: "a1", B: "b1"}}
+	testValues(t, []TestCaseB{
+		{b: testStruct.Var1, want: want},
+		{b: testStruct.Var2, want: []*TestIndexedItem{&want[0], &want[1]}},
+		{b: *old, want: TestIndexedItem{A: "a0", B: "b0"}},
+	})
+	if err := ReloadChanged(&testStruct, []string{"ITEM_2_A"}); err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, TestIndexedItem{A: "a2"})
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: want}})
+}
+
+func TestNoOverwriteIndexed(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "a0",
+			env: "ITEM_0_A",
+		},
+		{
+			a:   "a1",
+			env: "ITEM_1_A",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestIndexedReloadStruct{Var1: []TestIndexedItem{{A: "keep"}}}
+	if err := LoadEnv(&testStruct, WithNoOverwrite()); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{
+		{b: testStruct.Var1, want: []TestIndexedItem{{A: "keep"}}},
+		{b: len(testStruct.Var2), want: 2},
+	})
+}
+
+type TestAllEnvStruct struct {
+	Var1 map[string]string `env_parser:"allenv"`
+	Var2 map[string]string `env_parser:"allenv" env_params:"prefix=ALL_"`
+}
+
+func TestLoadAllEnv(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "ALL_VAR_1",
+		},
+		{
+			a:   testString,
+			env: "OTHER_VAR_1",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestAllEnvStruct{}
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1["ALL_VAR_1"],
+			want: testString,
+		},
+		{
+			b:    testStruct.Var1["OTHER_VAR_1"],
+			want: testString,
+		},
+		{
+			b:    testStruct.Var2,
+			want: map[string]string{"ALL_VAR_1": testString},
+		},
+	}
+	testValues(t, testCasesB)
+	testStruct = TestAllEnvStruct{Var2: map[string]string{"ALL_VAR_2": "x"}}
+	if err := LoadEnv(&testStruct, WithNoOverwrite()); err != nil {
+		t.Error(err)
+	}
+	testCasesB = []TestCaseB{
+		{
+			b:    testStruct.Var1["ALL_VAR_1"],
+			want: testString,
+		},
+		{
+			b:    testStruct.Var2,
+			want: map[string]string{"ALL_VAR_2": "x"},
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+type TestLabels map[string]string
+
+type TestHosts []string
+
+type TestName string
+
+type TestFlag bool
+
+type TestNamedTypeStruct struct {
+	Var1       TestLabels  `env_var:"NAMED_VAR_1"`
+	Var1NilPtr *TestLabels `env_var:"NAMED_VAR_1"`
+	Var2       TestHosts   `env_var:"NAMED_VAR_2"`
+	Var2NilPtr *TestHosts  `env_var:"NAMED_VAR_2"`
+	Var3       TestName    `env_var:"NAMED_VAR_3"`
+	Var4       TestFlag    `env_var:"NAMED_VAR_4"`
+}
+
+func TestLoadNamedTypes(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"a":"b"}`,
+			env: "NAMED_VAR_1",
+		},
+		{
+			a:   `["a","b"]`,
+			env: "NAMED_VAR_2",
+		},
+		{
+			a:   testString,
+			env: "NAMED_VAR_3",
+		},
+		{
+			a:   "true",
+			env: "NAMED_VAR_4",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestNamedTypeStruct{}
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: TestLabels{"a": "b"},
+		},
+		{
+			b:    *testStruct.Var1NilPtr,
+			want: TestLabels{"a": "b"},
+		},
+		{
+			b:    testStruct.Var2,
+			want: TestHosts{"a", "b"},
+		},
+		{
+			b:    *testStruct.Var2NilPtr,
+			want: TestHosts{"a", "b"},
+		},
+		{
+			b:    testStruct.Var3,
+			want: TestName(testString),
+		},
+		{
+			b:    testStruct.Var4,
+			want: TestFlag(true),
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestValidateEnv(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "VAR_1",
+		},
+		{
+			a:   testString,
+			env: "SUB_VAR",
+		},
+		{
+			a:   strconv.FormatInt(testInt64, 10),
+			env: "VAR_2",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := newTestStruct()
+	if err := ValidateEnv(&testStruct); err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(testStruct, newTestStruct()) {
+		t.Errorf("b = %v; want %v", testStruct, newTestStruct())
+	}
+	if err := setEnv([]TestCaseA{{a: testString, env: "VAR_3"}}); err != nil {
+		panic(err)
+	}
+	defer os.Unsetenv("VAR_3")
+	if err := ValidateEnv(&testStruct); err == nil {
+		t.Error("missing error")
+	}
+	testRequiredStruct := TestRequiredStruct{Var1: defaultString}
+	if err := ValidateEnv(&testRequiredStruct); err == nil {
+		t.Error("missing error")
+	}
+	testValues(t, []TestCaseB{
+		{
+			b:    testRequiredStruct,
+			want: TestRequiredStruct{Var1: defaultString},
+		},
+	})
+}
+
+type TestPresenceStruct struct {
+	Var1       bool  `env_var:"PRESENCE_VAR_1" env_params:"presence=true"`
+	Var1NilPtr *bool `env_var:"PRESENCE_VAR_1" env_params:"presence=true"`
+}
+
+func TestLoadPresence(t *testing.T) {
+	for _, val := range []string{"", "garbage"} {
+		testCasesA := []TestCaseA{
+			{
+				a:   val,
+				env: "PRESENCE_VAR_1",
+			},
+		}
+		testStruct := TestPresenceStruct{}
+		if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+			t.Error(err)
+		}
+		testCasesB := []TestCaseB{
+			{
+				b:    testStruct.Var1,
+				want: true,
+			},
+			{
+				b:    *testStruct.Var1NilPtr,
+				want: true,
+			},
+		}
+		testValues(t, testCasesB)
+	}
+	testStruct := TestPresenceStruct{Var1: true}
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: false,
+		},
+		{
+			b:    *testStruct.Var1NilPtr,
+			want: false,
+		},
+	}
+	testValues(t, testCasesB)
+	testStruct = TestPresenceStruct{Var1: true, Var1NilPtr: new(bool)}
+	if err := LoadEnv(&testStruct, WithNoOverwrite()); err != nil {
+		t.Error(err)
+	}
+	testCasesB = []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: true,
+		},
+		{
+			b:    *testStruct.Var1NilPtr,
+			want: false,
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestValidateEnvPresence(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "PRESENCE_VAR_1",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	ptr := new(bool)
+	testStruct := TestPresenceStruct{Var1NilPtr: ptr}
+	if err := ValidateEnv(&testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: false,
+		},
+		{
+			b:    testStruct.Var1NilPtr == ptr,
+			want: true,
+		},
+		{
+			b:    *ptr,
+			want: false,
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+type TestUseNumberStruct struct {
+	Var1 map[string]interface{} `env_var:"NUM_VAR_1"`
+	Var2 map[string]interface{} `env_var:"NUM_VAR_1" env_params:"usenumber=true"`
+}
+
+func TestLoadUseNumber(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"a":9007199254740993}`,
+			env: "NUM_VAR_1",
+		},
+	}
+	testStruct := TestUseNumberStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1["a"],
+			want: float64(9007199254740993),
+		},
+		{
+			b:    testStruct.Var2["a"],
+			want: json.Number("9007199254740993"),
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestLoadIntOverflow(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "300",
+			env: "VAR_3",
+		},
+	}
+	testStruct := TestStruct{}
+	err := loadTestStruct(t, testCasesA, &testStruct)
+	if !errors.Is(err, strconv.ErrRange) {
+		t.Fatalf("err = %v; want %v", err, strconv.ErrRange)
+	}
+	for _, s := range []string{"value 300 overflows int8", "Var3", "VAR_3"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("err = %v; want %s", err, s)
+		}
+	}
+}
+
+type TestVersion struct {
+	Major, Minor int
+}
+
+func (v *TestVersion) SetFromEnv(val string) error {
+	_, err := fmt.Sscanf(val, "v%d.%d", &v.Major, &v.Minor)
+	return err
+}
+
+type TestEnvSetterStruct struct {
+	Var1       TestVersion  `env_var:"SETTER_VAR_1"`
+	Var1NilPtr *TestVersion `env_var:"SETTER_VAR_1"`
+}
+
+func TestLoadEnvSetter(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "v1.2",
+			env: "SETTER_VAR_1",
+		},
+	}
+	testStruct := TestEnvSetterStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: TestVersion{Major: 1, Minor: 2},
+		},
+		{
+			b:    *testStruct.Var1NilPtr,
+			want: TestVersion{Major: 1, Minor: 2},
+		},
+	}
+	testValues(t, testCasesB)
+	testCasesA[0].a = `{"Major": 1, "Minor": 2}`
+	if err := loadTestStruct(t, testCasesA, &testStruct); err == nil {
+		t.Error("missing error")
+	}
+}
+
+type TestSparseStruct struct {
+	Var1 []string `env_var:"SPARSE_VAR_1" env_params:"sparse=true"`
+	Var2 []int    `env_var:"SPARSE_VAR_2" env_params:"sparse=true"`
+}
+
+func TestLoadSparseSlice(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"0":"a","1":"b"}`,
+			env: "SPARSE_VAR_1",
+		},
+		{
+			a:   `{"0":1,"3":4}`,
+			env: "SPARSE_VAR_2",
+		},
+	}
+	testStruct := TestSparseStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: []string{"a", "b"},
+		},
+		{
+			b:    testStruct.Var2,
+			want: []int{1, 0, 0, 4},
+		},
+	}
+	testValues(t, testCasesB)
+	testCasesA = []TestCaseA{
+		{
+			a:   `["a","b"]`,
+			env: "SPARSE_VAR_1",
+		},
+	}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesA[0].a = `{"0":"a","x":"b"}`
+	if err := loadTestStruct(t, testCasesA, &testStruct); err == nil {
+		t.Error("missing error")
+	}
+}
+
+func TestLoadBoolTriState(t *testing.T) {
+	testTrue, testFalse := true, false
+	testCases := []struct {
+		a    string
+		set  bool
+		want *bool
+		err  bool
+	}{
+		{
+			a:    "true",
+			set:  true,
+			want: &testTrue,
+		},
+		{
+			a:    "false",
+			set:  true,
+			want: &testFalse,
+		},
+		{
+			a:   "maybe",
+			set: true,
+			err: true,
+		},
+		{
+			set: false,
+		},
+	}
+	for _, testCase := range testCases {
+		var testCasesA []TestCaseA
+		if testCase.set {
+			testCasesA = append(testCasesA, TestCaseA{a: testCase.a, env: "TRI_VAR_1"})
+		}
+		testStruct := struct {
+			Var1 *bool `env_var:"TRI_VAR_1"`
+		}{}
+		err := loadTestStruct(t, testCasesA, &testStruct)
+		if testCase.err {
+			if !errors.Is(err, strconv.ErrSyntax) {
+				t.Errorf("err = %v; want %v", err, strconv.ErrSyntax)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error(err)
+		}
+		testValues(t, []TestCaseB{{b: testStruct.Var1, want: testCase.want}})
+	}
+}
+
+type TestCompositePtrSubStruct struct {
+	Var1 *map[string]string `env_var:"COMP_VAR_1"`
+	Var2 *[]string          `env_var:"COMP_VAR_2"`
+	Var3 *map[string]int    `env_var:"COMP_VAR_3" env_parser:"kvmap"`
+	Var4 *[]int             `env_var:"COMP_VAR_4" env_parser:"list"`
+}
+
+type TestCompositePtrStruct struct {
+	TestCompositePtrSubStruct
+	Sub    *TestCompositePtrSubStruct
+	NilSub *TestCompositePtrSubStruct
+}
+
+func TestLoadCompositePtr(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"a":"b"}`,
+			env: "COMP_VAR_1",
+		},
+		{
+			a:   `["a","b"]`,
+			env: "COMP_VAR_2",
+		},
+		{
+			a:   "a=1",
+			env: "COMP_VAR_3",
+		},
+		{
+			a:   "1,2",
+			env: "COMP_VAR_4",
+		},
+	}
+	testStruct := TestCompositePtrStruct{Sub: &TestCompositePtrSubStruct{}}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []*TestCompositePtrSubStruct{&testStruct.TestCompositePtrSubStruct, testStruct.Sub, testStruct.NilSub} {
+		if sub == nil || sub.Var1 == nil || sub.Var2 == nil || sub.Var3 == nil || sub.Var4 == nil {
+			t.Fatalf("nil pointer in %+v", sub)
+		}
+		testCasesB := []TestCaseB{
+			{
+				b:    *sub.Var1,
+				want: map[string]string{"a": "b"},
+			},
+			{
+				b:    *sub.Var2,
+				want: []string{"a", "b"},
+			},
+			{
+				b:    *sub.Var3,
+				want: map[string]int{"a": 1},
+			},
+			{
+				b:    *sub.Var4,
+				want: []int{1, 2},
+			},
+		}
+		testValues(t, testCasesB)
+	}
+	testStruct = TestCompositePtrStruct{}
+	if err := loadTestStruct(t, nil, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestCompositePtrStruct{}}})
+}
+
+type TestCommonInner struct {
+	Var string `env_var:"COMMON_INNER_VAR"`
+}
+
+type TestCommon struct {
+	Name  string `env_var:"COMMON_NAME"`
+	Inner *TestCommonInner
+}
+
+type TestEmbeddedPtrStruct struct {
+	*TestCommon
+	Var1 string `env_var:"COMMON_VAR_1"`
+}
+
+func TestLoadEmbeddedPtr(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "COMMON_NAME",
+		},
+	}
+	testStruct := TestEmbeddedPtrStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	if testStruct.TestCommon == nil {
+		t.Fatal("embedded pointer not allocated")
+	}
+	testValues(t, []TestCaseB{{b: *testStruct.TestCommon, want: TestCommon{Name: testString}}})
+	testCasesA = []TestCaseA{
+		{
+			a:   testString,
+			env: "COMMON_INNER_VAR",
+		},
+	}
+	testStruct = TestEmbeddedPtrStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	if testStruct.TestCommon == nil || testStruct.Inner == nil {
+		t.Fatal("embedded pointer not allocated")
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Inner.Var, want: testString}})
+	testStruct = TestEmbeddedPtrStruct{}
+	if err := loadTestStruct(t, nil, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestEmbeddedPtrStruct{}}})
+}
+
+type TestIndirectStruct struct {
+	Var1 string `env_var:"INDIRECT_VAR_1" env_parser:"indirect"`
+	Var2 int    `env_var:"INDIRECT_VAR_2" env_parser:"indirect"`
+}
+
+func TestLoadIndirect(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "INDIRECT_TARGET_1",
+			env: "INDIRECT_VAR_1",
+		},
+		{
+			a:   "INDIRECT_TARGET_2",
+			env: "INDIRECT_VAR_2",
+		},
+		{
+			a:   "INDIRECT_VAR_1",
+			env: "INDIRECT_TARGET_1",
+		},
+		{
+			a:   "42",
+			env: "INDIRECT_TARGET_2",
+		},
+	}
+	testStruct := TestIndirectStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: "INDIRECT_VAR_1"}, {b: testStruct.Var2, want: 42}})
+	err := loadTestStruct(t, testCasesA[:3], &TestIndirectStruct{})
+	if err == nil || !strings.Contains(err.Error(), "env var 'INDIRECT_TARGET_2' referenced by 'INDIRECT_VAR_2' not set") {
+		t.Errorf("err = %v; want missing target error", err)
+	}
+	testCasesA[0].a = "INDIRECT_VAR_1"
+	err = loadTestStruct(t, testCasesA, &TestIndirectStruct{})
+	if err == nil || !strings.Contains(err.Error(), "env var 'INDIRECT_VAR_1' references itself") {
+		t.Errorf("err = %v; want self reference error", err)
+	}
+}
+
+type TestAppendStruct struct {
+	Var1 []string  `env_var:"APPEND_VAR_1" env_params:"append=true"`
+	Var2 *[]string `env_var:"APPEND_VAR_1" env_params:"append=true"`
+	Var3 []int     `env_var:"APPEND_VAR_2" env_parser:"list" env_params:"append=true"`
+	Var4 []string  `env_var:"APPEND_VAR_1"`
+}
+
+func TestLoadAppend(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `["b","c"]`,
+			env: "APPEND_VAR_1",
+		},
+		{
+			a:   "2,3",
+			env: "APPEND_VAR_2",
+		},
+	}
+	defaults := make([]string, 1, 10)
+	defaults[0] = "a"
+	ptrDefaults := []string{"x"}
+	testStruct := TestAppendStruct{Var1: defaults, Var2: &ptrDefaults, Var3: []int{1}, Var4: []string{"a"}}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: []string{"a", "b", "c"},
+		},
+		{
+			b:    *testStruct.Var2,
+			want: []string{"x", "b", "c"},
+		},
+		{
+			b:    testStruct.Var3,
+			want: []int{1, 2, 3},
+		},
+		{
+			b:    testStruct.Var4,
+			want: []string{"b", "c"},
+		},
+		{
+			b:    defaults[:2],
+			want: []string{"a", ""},
+		},
+	}
+	testValues(t, testCasesB)
+	testStruct = TestAppendStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: []string{"b", "c"}}, {b: *testStruct.Var2, want: []string{"b", "c"}}})
+}
+
+type TestMapSlice []map[string]string
+
+type TestMapSliceStruct struct {
+	Var1 []map[string]string  `env_var:"MAPSLICE_VAR_1"`
+	Var2 *[]map[string]string `env_var:"MAPSLICE_VAR_1"`
+	Var3 *[]map[string]string `env_var:"MAPSLICE_VAR_1"`
+	Var4 TestMapSlice         `env_var:"MAPSLICE_VAR_1"`
+	Var5 *TestMapSlice        `env_var:"MAPSLICE_VAR_1"`
+}
+
+func TestLoadMapSlice(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `[{"a":"1"},{"b":"2","c":"3"}]`,
+			env: "MAPSLICE_VAR_1",
+		},
+	}
+	existing := []map[string]string{{"x": "y"}}
+	testStruct := TestMapSliceStruct{Var2: &existing}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	if testStruct.Var2 == nil || testStruct.Var3 == nil || testStruct.Var5 == nil {
+		t.Fatalf("unexpected pointers in %+v", testStruct)
+	}
+	want := []map[string]string{{"a": "1"}, {"b": "2", "c": "3"}}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: want,
+		},
+		{
+			b:    *testStruct.Var2,
+			want: want,
+		},
+		{
+			b:    *testStruct.Var3,
+			want: want,
+		},
+		{
+			b:    testStruct.Var4,
+			want: TestMapSlice(want),
+		},
+		{
+			b:    *testStruct.Var5,
+			want: TestMapSlice(want),
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+type TestVerboseStruct struct {
+	Var1 int    `env_var:"VERBOSE_VAR_1"`
+	Var2 string `env_var:"VERBOSE_VAR_2" env_params:"secret=true"`
+	Var3 []int  `env_var:"VERBOSE_VAR_3" env_parser:"list"`
+	Var4 string `env_var:"VERBOSE_VAR_4"`
+	Var5 TestSubStruct
+	Var6 *TestPassword `env_var:"VERBOSE_VAR_6"`
+}
+
+func TestLoadEnvVerbose(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "1",
+			env: "VERBOSE_VAR_1",
+		},
+		{
+			a:   "pw",
+			env: "VERBOSE_VAR_2",
+		},
+		{
+			a:   "1,2",
+			env: "VERBOSE_VAR_3",
+		},
+		{
+			a:   "hunter2",
+			env: "VERBOSE_VAR_6",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer func() {
+		if err := unsetEnv(testCasesA); err != nil {
+			panic(err)
+		}
+	}()
+	var b strings.Builder
+	testStruct := TestVerboseStruct{Var4: defaultString}
+	if err := LoadEnvVerbose(&testStruct, &b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, line := range []string{
+		"Var1: env var 'VERBOSE_VAR_1' set to '1', parsed by default parser 'int' to '1'\n",
+		"Var2: env var 'VERBOSE_VAR_2' set to '***', parsed by default parser 'string' to '***'\n",
+		"Var3: env var 'VERBOSE_VAR_3' set to '1,2', parsed by built-in keyword parser 'list' to '[1 2]'\n",
+		"Var4: env var 'VERBOSE_VAR_4' not set, keeping 'default'\n",
+		"Var: env var 'SUB_VAR' not set, keeping ''\n",
+		"Var6: env var 'VERBOSE_VAR_6' set to '***', parsed by default parser 'string' to '<redacted>'\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("output missing %q:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "pw") || strings.Contains(out, "hunter2") {
+		t.Errorf("secret not redacted:\n%s", out)
+	}
+}
+
+func TestLoadEnvVerbosePaths(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"host":"h"}`,
+			env: "V_JSON",
+		},
+		{
+			a:   "",
+			env: "V_DEBUG",
+		},
+		{
+			a:   "a0",
+			env: "V_ITEM_0_A",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	var b strings.Builder
+	testStruct := struct {
+		Host  string            `env_group:"V_JSON"`
+		Port  int               `env_group:"V_JSON"`
+		User  string            `env_group:"V_OTHER_JSON"`
+		Debug bool              `env_var:"V_DEBUG" env_params:"presence=true"`
+		Env   map[string]string `env_parser:"allenv" env_params:"prefix=V_ITEM_"`
+		Items []TestIndexedItem `env_var:"V_ITEM" env_parser:"recurse"`
+	}{}
+	if err := LoadEnvVerbose(&testStruct, &b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, line := range []string{
+		"Host: set from group 'V_JSON' member 'Host' to 'h'\n",
+		"Port: group 'V_JSON' has no member 'Port', keeping '0'\n",
+		"User: group env var 'V_OTHER_JSON' not set, keeping ''\n",
+		"Debug: presence of env var 'V_DEBUG' is 'true'\n",
+		"Env: set from 1 env vars with prefix 'V_ITEM_'\n",
+		"A: env var 'V_ITEM_0_A' set to 'a0', parsed by default parser 'string' to 'a0'\n",
+		"Items: set from 1 elements with prefix 'V_ITEM_'\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("output missing %q:\n%s", line, out)
+		}
+	}
+}
+
+func TestLoadEnvVerboseFallback(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "hunter2",
+			env: "VERBOSE_VAR_1",
+		},
+		{
+			a:   "x",
+			env: "VERBOSE_VAR_2",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	var b strings.Builder
+	testStruct := struct {
+		Var1 int `env_var:"VERBOSE_VAR_1" env_params:"secret=true"`
+		Var2 int `env_var:"VERBOSE_VAR_2"`
+	}{Var1: 1}
+	if err := LoadEnvVerbose(&testStruct, &b, WithParseFallback(true)); err != nil {
+		t.Fatal(err)
+	}
+	want := "Var1: env var 'VERBOSE_VAR_1' set but parsing failed, keeping '***': ***\n" +
+		"Var2: env var 'VERBOSE_VAR_2' set but parsing failed, keeping '0': loading field 'Var2' from env var 'VERBOSE_VAR_2' failed: strconv.ParseInt: parsing \"x\": invalid syntax\n"
+	if b.String() != want {
+		t.Errorf("output = %q; want %q", b.String(), want)
+	}
+}
+
+type TestMaxItemsStruct struct {
+	Var1 []int    `env_var:"MAX_VAR_1" env_params:"maxitems=3"`
+	Var2 []string `env_var:"MAX_VAR_2" env_parser:"list" env_params:"maxitems=3"`
+}
+
+func TestLoadMaxItems(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		err  bool
+	}{
+		{
+			a: "[1,2]",
+			b: "a,b",
+		},
+		{
+			a: "[1,2,3]",
+			b: "a,b,c",
+		},
+		{
+			a:   "[1,2,3,4]",
+			b:   "a,b,c,d",
+			err: true,
+		},
+	}
+	for _, testCase := range testCases {
+		for _, testCaseA := range []TestCaseA{{a: testCase.a, env: "MAX_VAR_1"}, {a: testCase.b, env: "MAX_VAR_2"}} {
+			testStruct := TestMaxItemsStruct{}
+			err := loadTestStruct(t, []TestCaseA{testCaseA}, &testStruct)
+			if testCase.err {
+				if err == nil || !strings.Contains(err.Error(), "4 items exceed the maximum of 3") {
+					t.Errorf("err = %v; want maxitems error", err)
+				}
+			} else if err != nil {
+				t.Error(err)
+			}
+		}
+	}
+}
+
+type TestReloadStruct struct {
+	Var1       string         `env_var:"VAR_1"`
+	Var2       int            `env_var:"VAR_2" env_params:"required=true"`
+	Var3       TestSubStruct  `env_var:"VAR_3"`
+	Var3NilPtr *TestSubStruct `env_var:"VAR_3"`
+}
+
+func TestReloadChanged(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "VAR_1",
+		},
+		{
+			a:   testString,
+			env: "SUB_VAR",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer func() {
+		if err := unsetEnv(testCasesA); err != nil {
+			panic(err)
+		}
+	}()
+	testStruct := TestReloadStruct{Var1: defaultString, Var2: 1}
+	if err := ReloadChanged(&testStruct, []string{"VAR_1"}); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestReloadStruct{Var1: testString, Var2: 1}}})
+	testStruct = TestReloadStruct{Var1: defaultString, Var2: 1}
+	if err := ReloadChanged(&testStruct, []string{"SUB_VAR"}); err != nil {
+		t.Fatal(err)
+	}
+	sub := TestSubStruct{Var: testString, VarPtr: &testString, VarNilPtr: &testString}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestReloadStruct{Var1: defaultString, Var2: 1, Var3: sub, Var3NilPtr: &sub}}})
+	if err := ReloadChanged(&testStruct, []string{"VAR_2"}); err == nil {
+		t.Error("missing error")
+	}
+}
+
+type TestReloadPathsStruct struct {
+	Var1 string             `env_group:"RELOAD_JSON"`
+	Var2 TestQuerySubStruct `env_var:"RELOAD_QUERY" env_parser:"query"`
+	Var3 bool               `env_var:"RELOAD_PRESENCE" env_params:"presence=true"`
+	Var4 map[string]string  `env_parser:"allenv" env_params:"prefix=RELOAD_"`
+	Var5 string             `env_var:"P_A"`
+}
+
+func TestReloadChangedPaths(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"var1":"new"}`,
+			env: "RELOAD_JSON",
+		},
+		{
+			a:   "host=new",
+			env: "RELOAD_QUERY",
+		},
+		{
+			a:   testString,
+			env: "P_A",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer func() {
+		if err := unsetEnv(testCasesA); err != nil {
+			panic(err)
+		}
+	}()
+	old := TestReloadPathsStruct{
+		Var1: "old",
+		Var2: TestQuerySubStruct{Host: "old"},
+		Var3: true,
+		Var4: map[string]string{"RELOAD_X": "old"},
+	}
+	testStruct := old
+	if err := ReloadChanged(&testStruct, []string{"P_A"}); err != nil {
+		t.Fatal(err)
+	}
+	want := old
+	want.Var5 = testString
+	testValues(t, []TestCaseB{{b: testStruct, want: want}})
+	testStruct = old
+	if err := ReloadChanged(&testStruct, []string{"RELOAD_JSON", "RELOAD_QUERY", "RELOAD_PRESENCE"}); err != nil {
+		t.Fatal(err)
+	}
+	want = TestReloadPathsStruct{
+		Var1: "new",
+		Var2: TestQuerySubStruct{Host: "new"},
+		Var4: map[string]string{"RELOAD_JSON": `{"var1":"new"}`, "RELOAD_QUERY": "host=new"},
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: want}})
+}
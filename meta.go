@@ -0,0 +1,128 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// field holds the tag values of an exported struct field. Instances are shared between loads
+// and must not be modified.
+type field struct {
+	index          int
+	st             reflect.StructField
+	name           string
+	tagged         bool
+	parserKw       string
+	params         []string
+	kwParams       map[string]string
+	jsonName       string
+	group          string
+	groupKey       string
+	requiredGroup  string
+	exclusiveGroup string
+	prefix         string
+	namePrefix     string
+	doc            string
+	keyOrder       string
+}
+
+var fieldCache sync.Map
+
+// subPrefix returns the prefix for the fields of a nested struct, joined with the env_prefix tag if present.
+func (f *field) subPrefix(prefix string) string {
+	if f.prefix != "" {
+		return prefix + f.prefix + "_"
+	}
+	return prefix
+}
+
+// upperSnake converts a Go identifier to upper snake case, e.g. DBHostName to DB_HOST_NAME.
+func upperSnake(name string) string {
+	r := []rune(name)
+	var b strings.Builder
+	for i, c := range r {
+		if i > 0 && unicode.IsUpper(c) && (!unicode.IsUpper(r[i-1]) || (i+1 < len(r) && unicode.IsLower(r[i+1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(c))
+	}
+	return b.String()
+}
+
+func getParams(st reflect.StructField) (params []string, kwParams map[string]string) {
+	if prms, k := st.Tag.Lookup(paramsTag); k && prms != "" {
+		parts := strings.Split(prms, separator)
+		for _, v := range parts {
+			if strings.Contains(v, equal) {
+				if kwParams == nil {
+					kwParams = make(map[string]string)
+				}
+				kp := strings.SplitN(v, equal, 2)
+				kwParams[kp[0]] = kp[1]
+			} else {
+				params = append(params, v)
+			}
+		}
+	}
+	return
+}
+
+func newFields(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		st := t.Field(i)
+		if st.PkgPath != "" {
+			continue
+		}
+		f := field{index: i, st: st}
+		f.name, f.tagged = st.Tag.Lookup(varTag)
+		f.tagged = f.tagged && f.name != ""
+		f.parserKw = st.Tag.Get(parserTag)
+		f.params, f.kwParams = getParams(st)
+		if name, _, _ := strings.Cut(st.Tag.Get("json"), ","); name != "-" {
+			f.jsonName = name
+		}
+		f.prefix = st.Tag.Get(prefixTag)
+		if !st.Anonymous {
+			f.namePrefix = upperSnake(st.Name)
+		}
+		f.doc = st.Tag.Get(docTag)
+		f.keyOrder = st.Tag.Get(keyOrderTag)
+		f.requiredGroup = st.Tag.Get(groupRequiredTag)
+		f.exclusiveGroup = st.Tag.Get(groupExclusiveTag)
+		if f.group = st.Tag.Get(groupTag); f.group != "" {
+			f.groupKey = st.Name
+			if f.jsonName != "" {
+				f.groupKey = f.jsonName
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func getFields(t reflect.Type) []field {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]field)
+	}
+	fields, _ := fieldCache.LoadOrStore(t, newFields(t))
+	return fields.([]field)
+}
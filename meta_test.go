@@ -0,0 +1,93 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFieldCache(t *testing.T) {
+	for _, typ := range []reflect.Type{reflect.TypeOf(TestStruct{}), reflect.TypeOf(TestSubStruct{}), reflect.TypeOf(TestMapKeyStruct{})} {
+		cached := getFields(typ)
+		if !reflect.DeepEqual(cached, newFields(typ)) {
+			t.Errorf("cached fields of %s differ from uncached fields", typ)
+		}
+		if again := getFields(typ); len(again) > 0 && &again[0] != &cached[0] {
+			t.Errorf("fields of %s not cached", typ)
+		}
+	}
+}
+
+func TestLoadCached(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "VAR_1",
+		},
+		{
+			a:   "1",
+			env: "VAR_2",
+		},
+		{
+			a:   `{"var":"test"}`,
+			env: "VAR_17",
+		},
+	}
+	a := initTestStruct(t, testCasesA, nil, nil, nil)
+	b := initTestStruct(t, testCasesA, nil, nil, nil)
+	testValues(t, []TestCaseB{{b: b, want: a}})
+}
+
+func BenchmarkLoadEnv(b *testing.B) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "VAR_1",
+		},
+		{
+			a:   "1",
+			env: "VAR_2",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer func() {
+		if err := unsetEnv(testCasesA); err != nil {
+			panic(err)
+		}
+	}()
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			testStruct := newTestStruct()
+			if err := LoadEnv(&testStruct); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			fieldCache.Delete(reflect.TypeOf(TestStruct{}))
+			fieldCache.Delete(reflect.TypeOf(TestSubStruct{}))
+			testStruct := newTestStruct()
+			if err := LoadEnv(&testStruct); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
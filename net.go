@@ -0,0 +1,99 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+var macParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	return net.ParseMAC(val)
+}
+
+var ipNetParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	_, ipNet, err := net.ParseCIDR(val)
+	return ipNet, err
+}
+
+var urlValuesParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	return url.ParseQuery(val)
+}
+
+var netAddrType = reflect.TypeOf((*net.Addr)(nil)).Elem()
+
+// HostAddr is a network address with a host name, which is resolved when dialing instead of while loading.
+type HostAddr struct {
+	Net  string
+	Addr string
+}
+
+func (a *HostAddr) Network() string {
+	return a.Net
+}
+
+func (a *HostAddr) String() string {
+	return a.Addr
+}
+
+// parseHostPort builds a TCP or UDP address without DNS lookups, host names result in a *HostAddr.
+func parseHostPort(network, addr string) (net.Addr, error) {
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := net.LookupPort(network, p)
+	if err != nil {
+		return nil, err
+	}
+	host, zone, _ := strings.Cut(host, "%")
+	ip := net.ParseIP(host)
+	if ip == nil && host != "" {
+		return &HostAddr{Net: network, Addr: addr}, nil
+	}
+	if ip != nil && ((strings.HasSuffix(network, "4") && ip.To4() == nil) || (strings.HasSuffix(network, "6") && ip.To4() != nil)) {
+		return nil, fmt.Errorf("address '%s' doesn't match network '%s'", host, network)
+	}
+	if strings.HasPrefix(network, "tcp") {
+		return &net.TCPAddr{IP: ip, Port: port, Zone: zone}, nil
+	}
+	return &net.UDPAddr{IP: ip, Port: port, Zone: zone}, nil
+}
+
+var netAddrParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if t != netAddrType {
+		return nil, fmt.Errorf("'%s' provided but '%s' required", t, netAddrType)
+	}
+	scheme, addr, ok := strings.Cut(val, "://")
+	if !ok {
+		return nil, fmt.Errorf("missing scheme in '%s'", val)
+	}
+	switch scheme {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+		return parseHostPort(scheme, addr)
+	case "unix", "unixgram", "unixpacket":
+		if addr == "" {
+			return nil, fmt.Errorf("missing socket path in '%s'", val)
+		}
+		return net.ResolveUnixAddr(scheme, addr)
+	default:
+		return nil, fmt.Errorf("unsupported scheme '%s'", scheme)
+	}
+}
@@ -0,0 +1,227 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type TestMacStruct struct {
+	Var1       net.HardwareAddr  `env_var:"MAC_VAR_1"`
+	Var1NilPtr *net.HardwareAddr `env_var:"MAC_VAR_1"`
+	Var2       net.HardwareAddr  `env_var:"MAC_VAR_2"`
+}
+
+func TestLoadMac(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "00:00:5e:00:53:01",
+			env: "MAC_VAR_1",
+		},
+		{
+			a:   "00-00-5E-00-53-02",
+			env: "MAC_VAR_2",
+		},
+	}
+	testStruct := TestMacStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: net.HardwareAddr{0x00, 0x00, 0x5e, 0x00, 0x53, 0x01},
+		},
+		{
+			b:    *testStruct.Var1NilPtr,
+			want: net.HardwareAddr{0x00, 0x00, 0x5e, 0x00, 0x53, 0x01},
+		},
+		{
+			b:    testStruct.Var2,
+			want: net.HardwareAddr{0x00, 0x00, 0x5e, 0x00, 0x53, 0x02},
+		},
+	}
+	testValues(t, testCasesB)
+	testCasesA = []TestCaseA{
+		{
+			a:   "00:00:5e:00:53",
+			env: "MAC_VAR_1",
+		},
+	}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err == nil || !strings.Contains(err.Error(), "00:00:5e:00:53") {
+		t.Errorf("err = %v; want error containing input", err)
+	}
+}
+
+type TestUrlValuesStruct struct {
+	Var1 url.Values `env_var:"QUERY_VAR_1"`
+}
+
+func TestLoadUrlValues(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "a=1&b=2&a=3&c=%20d",
+			env: "QUERY_VAR_1",
+		},
+	}
+	testStruct := TestUrlValuesStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: url.Values{"a": {"1", "3"}, "b": {"2"}, "c": {" d"}},
+		},
+	}
+	testValues(t, testCasesB)
+	testCasesA[0].a = "a=%zz"
+	if err := loadTestStruct(t, testCasesA, &testStruct); err == nil {
+		t.Error("missing error")
+	}
+}
+
+type TestNetAddrStruct struct {
+	Var1 net.Addr `env_var:"ADDR_VAR_1" env_parser:"netaddr"`
+}
+
+func TestLoadNetAddr(t *testing.T) {
+	testCases := []struct {
+		a       string
+		network string
+		addr    string
+		typ     string
+	}{
+		{
+			a:       "tcp://127.0.0.1:8080",
+			network: "tcp",
+			addr:    "127.0.0.1:8080",
+			typ:     "*net.TCPAddr",
+		},
+		{
+			a:       "udp://[::1]:53",
+			network: "udp",
+			addr:    "[::1]:53",
+			typ:     "*net.UDPAddr",
+		},
+		{
+			a:       "unix:///var/run/app.sock",
+			network: "unix",
+			addr:    "/var/run/app.sock",
+			typ:     "*net.UnixAddr",
+		},
+		{
+			a:       "tcp://db.invalid:5432",
+			network: "tcp",
+			addr:    "db.invalid:5432",
+			typ:     "*envldr.HostAddr",
+		},
+		{
+			a:       "udp6://[fe80::1%eth0]:53",
+			network: "udp",
+			addr:    "[fe80::1%eth0]:53",
+			typ:     "*net.UDPAddr",
+		},
+	}
+	for _, testCase := range testCases {
+		testStruct := TestNetAddrStruct{}
+		if err := loadTestStruct(t, []TestCaseA{{a: testCase.a, env: "ADDR_VAR_1"}}, &testStruct); err != nil {
+			t.Fatal(err)
+		}
+		testCasesB := []TestCaseB{
+			{
+				b:    fmt.Sprintf("%T", testStruct.Var1),
+				want: testCase.typ,
+			},
+			{
+				b:    testStruct.Var1.Network(),
+				want: testCase.network,
+			},
+			{
+				b:    testStruct.Var1.String(),
+				want: testCase.addr,
+			},
+		}
+		testValues(t, testCasesB)
+	}
+	for _, val := range []string{"http://localhost:80", "localhost:80", "unix://", "tcp://localhost", "tcp4://[::1]:80", "tcp6://127.0.0.1:80", "tcp://localhost:port"} {
+		testStruct := TestNetAddrStruct{}
+		if err := loadTestStruct(t, []TestCaseA{{a: val, env: "ADDR_VAR_1"}}, &testStruct); err == nil {
+			t.Errorf("missing error for %s", val)
+		}
+	}
+}
+
+type TestIPNetStruct struct {
+	Var1 []*net.IPNet `env_var:"NET_VAR_1" env_parser:"list"`
+	Var2 *net.IPNet   `env_var:"NET_VAR_2"`
+	Var3 []net.IPNet  `env_var:"NET_VAR_1" env_parser:"list"`
+}
+
+func TestLoadIPNet(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "10.0.0.0/8, 2001:db8::/32",
+			env: "NET_VAR_1",
+		},
+		{
+			a:   "192.168.1.7/24",
+			env: "NET_VAR_2",
+		},
+	}
+	testStruct := TestIPNetStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	var nets []string
+	for _, n := range testStruct.Var1 {
+		nets = append(nets, n.String())
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    nets,
+			want: []string{"10.0.0.0/8", "2001:db8::/32"},
+		},
+		{
+			b:    testStruct.Var2.String(),
+			want: "192.168.1.0/24",
+		},
+		{
+			b:    len(testStruct.Var3),
+			want: 2,
+		},
+		{
+			b:    testStruct.Var3[0].Contains(net.ParseIP("10.1.2.3")),
+			want: true,
+		},
+	}
+	testValues(t, testCasesB)
+	testCasesA[0].a = "172.16.0.0/12"
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: len(testStruct.Var1), want: 1}, {b: testStruct.Var1[0].String(), want: "172.16.0.0/12"}})
+	testCasesA[0].a = "10.0.0.0/8,10.0.0.1"
+	err := loadTestStruct(t, testCasesA, &testStruct)
+	if err == nil || !strings.Contains(err.Error(), "element 1: ") {
+		t.Errorf("err = %v; want element 1 error", err)
+	}
+}
@@ -0,0 +1,244 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"flag"
+	"reflect"
+	"time"
+)
+
+type Option func(l *loader)
+
+// WithConflictCheck makes loading fail if sibling fields share an env var but differ in kind.
+func WithConflictCheck() Option {
+	return func(l *loader) {
+		l.conflictCheck = true
+	}
+}
+
+// WithNoOverwrite prevents env values from replacing fields that are not set to their zero value.
+func WithNoOverwrite() Option {
+	return func(l *loader) {
+		l.noOverwrite = true
+	}
+}
+
+// WithInterpolation expands $NAME and ${NAME} in values with the values of other env vars read during loading.
+func WithInterpolation() Option {
+	return func(l *loader) {
+		l.interpolate = true
+	}
+}
+
+// WithTwoPass gathers all values before interpolation, so references don't depend on field order.
+func WithTwoPass() Option {
+	return func(l *loader) {
+		l.interpolate = true
+		l.twoPass = true
+	}
+}
+
+// WithFlagSet falls back to flags of the given set if an env var is not set.
+// The optional mapper translates env var names to flag names.
+func WithFlagSet(fs *flag.FlagSet, mapper func(string) string) Option {
+	return func(l *loader) {
+		l.flagSet = fs
+		l.flagMapper = mapper
+	}
+}
+
+// WithSource falls back to the given source if an env var is not set. Can be given multiple times,
+// sources are queried in the given order.
+func WithSource(s Source) Option {
+	return func(l *loader) {
+		l.sources = append(l.sources, s)
+	}
+}
+
+// WithFieldNamePrefix prefixes the fields of nested structs without env_prefix tag with the
+// upper snake case field name, e.g. Database.Host resolves DATABASE_HOST.
+func WithFieldNamePrefix(enabled bool) Option {
+	return func(l *loader) {
+		l.fieldNamePrefix = enabled
+	}
+}
+
+// WithRetry calls a failing parser up to retries more times, waiting delay between attempts.
+func WithRetry(retries int, delay time.Duration) Option {
+	return func(l *loader) {
+		l.retries = retries
+		l.retryDelay = delay
+	}
+}
+
+// WithParseFallback keeps the current value of a field instead of failing if its parser fails.
+func WithParseFallback(enabled bool) Option {
+	return func(l *loader) {
+		l.parseFallback = enabled
+	}
+}
+
+// WithFinalizer calls f with the struct after all fields have been loaded, e.g. to derive computed fields.
+// An error of f is returned by the load. Can be given multiple times, finalizers aren't called by ValidateEnv.
+func WithFinalizer(f func(itf interface{}) error) Option {
+	return func(l *loader) {
+		l.finalizers = append(l.finalizers, f)
+	}
+}
+
+// WithSecretReveal reveals the given number of leading characters of redacted secrets in verbose output
+// and Dump, the remaining characters are masked.
+func WithSecretReveal(n int) Option {
+	return func(l *loader) {
+		l.secretReveal = n
+	}
+}
+
+// WithDefaultsFile uses the key value pairs of a .env file as defaults for env vars that are not set.
+// Unlike LoadFile the environment is not modified.
+func WithDefaultsFile(path string) Option {
+	return func(l *loader) {
+		l.defaultsFile = path
+	}
+}
+
+// WithRecover converts panics of parsers into errors.
+func WithRecover(enabled bool) Option {
+	return func(l *loader) {
+		l.recover = enabled
+	}
+}
+
+// WithSnapshot reads the environment once at the start of loading and resolves all env vars from that snapshot.
+func WithSnapshot() Option {
+	return func(l *loader) {
+		l.useSnapshot = true
+	}
+}
+
+// WithDecorator applies the decorator to the type or kind parser of fields referencing it via the env_parser tag.
+func WithDecorator(name string, d Decorator) Option {
+	return func(l *loader) {
+		if l.decorators == nil {
+			l.decorators = make(map[string]Decorator)
+		}
+		l.decorators[name] = d
+	}
+}
+
+// WithPrefixFromEnv prefixes all env var names with the value of the given env var.
+func WithPrefixFromEnv(name string) Option {
+	return func(l *loader) {
+		l.prefixVar = name
+	}
+}
+
+// WithOnDeprecated calls f with the env var name and note if an env var marked via the deprecated parameter is set.
+func WithOnDeprecated(f func(envVar, note string)) Option {
+	return func(l *loader) {
+		l.onDeprecated = f
+	}
+}
+
+// WithPrompt enables interactive mode, f is called for fields with the prompt parameter if their env var is not set.
+func WithPrompt(f PromptFunc) Option {
+	return func(l *loader) {
+		l.prompt = f
+	}
+}
+
+// WithUpperCase converts the names of env_var tags to upper case before resolving them.
+func WithUpperCase() Option {
+	return func(l *loader) {
+		l.upperCase = true
+	}
+}
+
+// WithErrorFormatter replaces the message of returned LoadErrors with the result of f.
+func WithErrorFormatter(f ErrorFormatter) Option {
+	return func(l *loader) {
+		l.errorFormatter = f
+	}
+}
+
+// WithJsonNames resolves fields without env_var tag from the upper case name of their json tag.
+func WithJsonNames() Option {
+	return func(l *loader) {
+		l.jsonNames = true
+	}
+}
+
+// WithUpperCaseFallback resolves env vars from the upper case form of their name if the name itself is not set.
+func WithUpperCaseFallback() Option {
+	return func(l *loader) {
+		l.upperCaseFallback = true
+	}
+}
+
+// WithKindParser uses p for fields of the given kind, it overrides the default parser and kind parsers passed to LoadEnvUserParser.
+func WithKindParser(kind reflect.Kind, p Parser) Option {
+	return func(l *loader) {
+		kindParsers := make(map[reflect.Kind]Parser, len(l.kindParsers)+1)
+		for k, v := range l.kindParsers {
+			kindParsers[k] = v
+		}
+		kindParsers[kind] = p
+		l.kindParsers = kindParsers
+	}
+}
+
+// WithStripComments removes inline comments starting with an unquoted '#' from values of numeric and bool fields.
+func WithStripComments() Option {
+	return func(l *loader) {
+		l.stripComments = true
+	}
+}
+
+// WithStrictPrefix makes loading fail if env vars with the given prefix exist that are not read by any field.
+func WithStrictPrefix(prefix string) Option {
+	return func(l *loader) {
+		l.strictPrefix = prefix
+		l.used = make(map[string]bool)
+	}
+}
+
+// WithNullSentinel resets fields to their zero value if their env var holds the sentinel value, e.g. NULL.
+func WithNullSentinel(sentinel string) Option {
+	return func(l *loader) {
+		l.nullSentinel = sentinel
+	}
+}
+
+// WithUnmarshaler makes fields with env_parser:"<name>" decode their value with unmarshal, e.g. yaml.Unmarshal,
+// without adding a dependency to this package.
+func WithUnmarshaler(name string, unmarshal func(data []byte, v interface{}) error) Option {
+	return func(l *loader) {
+		kwParsers := make(map[string]Parser, len(l.kwParsers)+1)
+		for k, v := range l.kwParsers {
+			kwParsers[k] = v
+		}
+		kwParsers[name] = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+			ptr := reflect.New(t)
+			if err := unmarshal([]byte(val), ptr.Interface()); err != nil {
+				return nil, err
+			}
+			return ptr.Interface(), nil
+		}
+		l.kwParsers = kwParsers
+	}
+}
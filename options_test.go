@@ -0,0 +1,949 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type TestConflictStruct struct {
+	Var1 int    `env_var:"PORT"`
+	Var2 string `env_var:"PORT"`
+}
+
+type TestNoConflictStruct struct {
+	Var1 int  `env_var:"PORT"`
+	Var2 *int `env_var:"PORT"`
+}
+
+func TestConflictCheck(t *testing.T) {
+	testStruct := TestConflictStruct{}
+	if err := LoadEnv(&testStruct, WithConflictCheck()); err == nil {
+		t.Error("missing error")
+	}
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Error(err)
+	}
+	testStruct2 := TestNoConflictStruct{}
+	if err := LoadEnv(&testStruct2, WithConflictCheck()); err != nil {
+		t.Error(err)
+	}
+	testStruct3 := newTestStruct()
+	if err := LoadEnv(&testStruct3, WithConflictCheck()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNoOverwrite(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "VAR_1",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestStruct{Var1: "x"}
+	if err := LoadEnv(&testStruct, WithNoOverwrite()); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: "x",
+		},
+		{
+			b:    *testStruct.Var1NilPtr,
+			want: testString,
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+type TestInterpolationStruct struct {
+	Var1 string `env_var:"INT_VAR_1"`
+	Var2 string `env_var:"INT_VAR_2"`
+	Var3 TestInterpolationSubStruct
+}
+
+type TestInterpolationSubStruct struct {
+	Var string `env_var:"INT_SUB_VAR"`
+}
+
+func TestInterpolation(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "${INT_VAR_2}/a",
+			env: "INT_VAR_1",
+		},
+		{
+			a:   testString,
+			env: "INT_VAR_2",
+		},
+		{
+			a:   "$INT_VAR_2/b",
+			env: "INT_SUB_VAR",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestInterpolationStruct{}
+	if err := LoadEnv(&testStruct, WithInterpolation()); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: "/a",
+		},
+		{
+			b:    testStruct.Var3.Var,
+			want: testString + "/b",
+		},
+	}
+	testValues(t, testCasesB)
+	testStruct = TestInterpolationStruct{}
+	if err := LoadEnv(&testStruct, WithTwoPass()); err != nil {
+		t.Error(err)
+	}
+	testCasesB = []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: testString + "/a",
+		},
+		{
+			b:    testStruct.Var3.Var,
+			want: testString + "/b",
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestFlagSet(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("var-1", "", "")
+	fs.Int("var-2", 0, "")
+	if err := fs.Parse([]string{"-var-1", testString}); err != nil {
+		panic(err)
+	}
+	mapper := func(name string) string {
+		return strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+	}
+	testStruct := TestStruct{Var2: 3}
+	if err := LoadEnv(&testStruct, WithFlagSet(fs, mapper)); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: testString,
+		},
+		{
+			b:    testStruct.Var2,
+			want: 3,
+		},
+	}
+	testValues(t, testCasesB)
+	testCasesA := []TestCaseA{
+		{
+			a:   defaultString,
+			env: "VAR_1",
+		},
+	}
+	testStruct = TestStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithFlagSet(fs, mapper)); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: defaultString,
+		},
+	})
+}
+
+func TestRecover(t *testing.T) {
+	kwParsers := map[string]Parser{
+		"testParser": func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+			panic(val)
+		},
+	}
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "VAR_25",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestStruct{}
+	err := LoadEnvUserParser(&testStruct, kwParsers, nil, nil, WithRecover(true))
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("err = %v; want %T", err, loadErr)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    loadErr.Field,
+			want: "Var25",
+		},
+		{
+			b:    loadErr.EnvVar,
+			want: "VAR_25",
+		},
+	}
+	testValues(t, testCasesB)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("missing panic")
+		}
+	}()
+	_ = LoadEnvUserParser(&testStruct, kwParsers, nil, nil, WithRecover(false))
+}
+
+type TestSnapshotStruct struct {
+	Var1 string `env_var:"SNAP_VAR_1" env_parser:"testHook"`
+	Var2 string `env_var:"SNAP_VAR_2"`
+}
+
+func TestSnapshot(t *testing.T) {
+	kwParsers := map[string]Parser{
+		"testHook": func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+			return val, os.Setenv("SNAP_VAR_2", defaultString)
+		},
+	}
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "SNAP_VAR_1",
+		},
+		{
+			a:   testString,
+			env: "SNAP_VAR_2",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestSnapshotStruct{}
+	if err := LoadEnvUserParser(&testStruct, kwParsers, nil, nil, WithSnapshot()); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{
+		{
+			b:    testStruct.Var2,
+			want: testString,
+		},
+	})
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	if err := LoadEnvUserParser(&testStruct, kwParsers, nil, nil); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{
+		{
+			b:    testStruct.Var2,
+			want: defaultString,
+		},
+	})
+}
+
+type TestDecoratorStruct struct {
+	Var1 string        `env_var:"DEC_VAR_1" env_parser:"lower"`
+	Var2 time.Duration `env_var:"DEC_VAR_2" env_parser:"lower"`
+}
+
+var testLowerDecorator Decorator = func(p Parser) Parser {
+	return func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+		return p(t, strings.ToLower(val), params, kwParams)
+	}
+}
+
+func TestDecorator(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "TEST",
+			env: "DEC_VAR_1",
+		},
+		{
+			a:   "1S",
+			env: "DEC_VAR_2",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestDecoratorStruct{}
+	if err := LoadEnvUserParser(&testStruct, nil, testTypeParsers, nil, WithDecorator("lower", testLowerDecorator)); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: "test",
+		},
+		{
+			b:    testStruct.Var2,
+			want: time.Second,
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestPrefixFromEnv(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "APP_",
+			env: "ENV_PREFIX",
+		},
+		{
+			a:   testString,
+			env: "APP_VAR_1",
+		},
+		{
+			a:   defaultString,
+			env: "VAR_1",
+		},
+		{
+			a:   testString,
+			env: "APP_SUB_VAR",
+		},
+	}
+	testStruct := TestStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithPrefixFromEnv("ENV_PREFIX")); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: testString,
+		},
+		{
+			b:    testStruct.Var17.Var,
+			want: testString,
+		},
+	}
+	testValues(t, testCasesB)
+	testStruct = TestStruct{}
+	if err := loadTestStruct(t, testCasesA[2:3], &testStruct, WithPrefixFromEnv("ENV_PREFIX")); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: defaultString,
+		},
+	})
+}
+
+type TestDeprecatedStruct struct {
+	Var1 string `env_var:"DEP_VAR_1" env_params:"deprecated=use NEW_VAR instead"`
+	Var2 string `env_var:"DEP_VAR_2" env_params:"deprecated=use OTHER_VAR instead"`
+}
+
+func TestOnDeprecated(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "DEP_VAR_1",
+		},
+	}
+	notes := make(map[string]string)
+	testStruct := TestDeprecatedStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithOnDeprecated(func(envVar, note string) {
+		notes[envVar] = note
+	})); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    notes,
+			want: map[string]string{"DEP_VAR_1": "use NEW_VAR instead"},
+		},
+		{
+			b:    testStruct.Var1,
+			want: testString,
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+type TestUpperCaseStruct struct {
+	Var1 string `env_var:"db_host"`
+	Var2 string `env_var:"Db_Port" env_params:"required=true"`
+}
+
+func TestUpperCase(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "DB_HOST",
+		},
+		{
+			a:   "5432",
+			env: "DB_PORT",
+		},
+	}
+	testStruct := TestUpperCaseStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithUpperCase()); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestUpperCaseStruct{Var1: testString, Var2: "5432"}}})
+	testStruct = TestUpperCaseStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err == nil {
+		t.Error("missing error")
+	}
+	var le *LoadError
+	if err := loadTestStruct(t, testCasesA[:1], &testStruct, WithUpperCase()); !errors.As(err, &le) || le.EnvVar != "DB_PORT" || le.Err.Error() != "env var 'DB_PORT' required but not set" {
+		t.Errorf("err = %v; want required error for DB_PORT", err)
+	}
+}
+
+func TestErrorFormatter(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "a",
+			env: "VAR_2",
+		},
+	}
+	formatter := func(le *LoadError) string {
+		return fmt.Sprintf("Ungültiger Wert für %s", le.EnvVar)
+	}
+	testStruct := TestStruct{}
+	err := loadTestStruct(t, testCasesA, &testStruct, WithErrorFormatter(formatter))
+	if err == nil || err.Error() != "Ungültiger Wert für VAR_2" {
+		t.Errorf("err = %v; want custom message", err)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("err = %v; want %v", err, strconv.ErrSyntax)
+	}
+	err = loadTestStruct(t, testCasesA, &testStruct)
+	if err == nil || !strings.HasPrefix(err.Error(), "loading field 'Var2' from env var 'VAR_2' failed: ") {
+		t.Errorf("err = %v; want default message", err)
+	}
+	err = LoadEnv(&TestRequiredStruct{}, WithErrorFormatter(formatter))
+	var le *LoadError
+	if !errors.As(err, &le) || le.Field != "Var2" || err.Error() != "Ungültiger Wert für REQ_VAR_2" {
+		t.Errorf("err = %v; want custom message", err)
+	}
+}
+
+type TestJsonNamesStruct struct {
+	Var1 string `json:"db_host"`
+	Var2 int    `json:"db_port,omitempty"`
+	Var3 string `json:"-"`
+	Var4 string `json:"db_user" env_var:"USER_NAME"`
+	Var5 string
+}
+
+func TestJsonNames(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "DB_HOST",
+		},
+		{
+			a:   "5432",
+			env: "DB_PORT",
+		},
+		{
+			a:   testString,
+			env: "USER_NAME",
+		},
+		{
+			a:   testString,
+			env: "VAR5",
+		},
+	}
+	testStruct := TestJsonNamesStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithJsonNames()); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestJsonNamesStruct{Var1: testString, Var2: 5432, Var4: testString}}})
+	testStruct = TestJsonNamesStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestJsonNamesStruct{Var4: testString}}})
+}
+
+type TestPrefixSubStruct struct {
+	Host string `env_var:"Host"`
+	Port int    `env_var:"PORT"`
+}
+
+type TestUpperCaseFallbackStruct struct {
+	Db    TestPrefixSubStruct  `env_prefix:"Db"`
+	Cache *TestPrefixSubStruct `env_prefix:"cache"`
+	Var1  string               `env_var:"Mixed_Case"`
+}
+
+func TestUpperCaseFallback(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "DB_HOST",
+		},
+		{
+			a:   "5432",
+			env: "Db_PORT",
+		},
+		{
+			a:   "6379",
+			env: "CACHE_PORT",
+		},
+		{
+			a:   testString,
+			env: "Mixed_Case",
+		},
+		{
+			a:   defaultString,
+			env: "MIXED_CASE",
+		},
+	}
+	testStruct := TestUpperCaseFallbackStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithUpperCaseFallback()); err != nil {
+		t.Fatal(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Db,
+			want: TestPrefixSubStruct{Host: testString, Port: 5432},
+		},
+		{
+			b:    testStruct.Cache,
+			want: &TestPrefixSubStruct{Port: 6379},
+		},
+		{
+			b:    testStruct.Var1,
+			want: testString,
+		},
+	}
+	testValues(t, testCasesB)
+	testStruct = TestUpperCaseFallbackStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestUpperCaseFallbackStruct{Db: TestPrefixSubStruct{Port: 5432}, Var1: testString}}})
+}
+
+type TestKindParserStruct struct {
+	Var1 bool   `env_var:"KP_VAR_1"`
+	Var2 string `env_var:"KP_VAR_2"`
+	Var3 int    `env_var:"KP_VAR_3"`
+}
+
+var testBoolParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	switch strings.ToLower(val) {
+	case "yes", "on":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(val)
+}
+
+func TestWithKindParser(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "yes",
+			env: "KP_VAR_1",
+		},
+		{
+			a:   testString,
+			env: "KP_VAR_2",
+		},
+		{
+			a:   "1",
+			env: "KP_VAR_3",
+		},
+	}
+	testStruct := TestKindParserStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithKindParser(reflect.Bool, testBoolParser)); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestKindParserStruct{Var1: true, Var2: testString, Var3: 1}}})
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	kindParsers := map[reflect.Kind]Parser{reflect.String: testKindParser}
+	testStruct = TestKindParserStruct{}
+	if err := LoadEnvUserParser(&testStruct, nil, nil, kindParsers, WithKindParser(reflect.Bool, testBoolParser)); err != nil {
+		t.Fatal(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct,
+			want: TestKindParserStruct{Var1: true, Var2: "prefix_" + testString, Var3: 1},
+		},
+		{
+			b:    len(kindParsers),
+			want: 1,
+		},
+	}
+	testValues(t, testCasesB)
+	if err := LoadEnv(&TestKindParserStruct{}); err == nil {
+		t.Error("missing error")
+	}
+}
+
+type TestStripCommentsStruct struct {
+	Var1 int      `env_var:"SC_VAR_1"`
+	Var2 *bool    `env_var:"SC_VAR_2"`
+	Var3 string   `env_var:"SC_VAR_3"`
+	Var4 float64  `env_var:"SC_VAR_4"`
+	Var5 []string `env_var:"SC_VAR_5" env_parser:"list"`
+}
+
+func TestStripComments(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "8080 # default port",
+			env: "SC_VAR_1",
+		},
+		{
+			a:   "true#enabled",
+			env: "SC_VAR_2",
+		},
+		{
+			a:   "color #fff",
+			env: "SC_VAR_3",
+		},
+		{
+			a:   "1.5",
+			env: "SC_VAR_4",
+		},
+		{
+			a:   "a#1,b",
+			env: "SC_VAR_5",
+		},
+	}
+	testStruct := TestStripCommentsStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithStripComments()); err != nil {
+		t.Fatal(err)
+	}
+	testBool := true
+	testValues(t, []TestCaseB{{b: testStruct, want: TestStripCommentsStruct{Var1: 8080, Var2: &testBool, Var3: "color #fff", Var4: 1.5, Var5: []string{"a#1", "b"}}}})
+	if err := loadTestStruct(t, testCasesA, &TestStripCommentsStruct{}); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("err = %v; want %v", err, strconv.ErrSyntax)
+	}
+	testValues(t, []TestCaseB{{b: stripComment(`"#1" # note`), want: `"#1"`}, {b: stripComment(`'#'`), want: `'#'`}})
+}
+
+type TestNullSentinelStruct struct {
+	Var1 string   `env_var:"NS_VAR_1"`
+	Var2 []string `env_var:"NS_VAR_2"`
+	Var3 *int     `env_var:"NS_VAR_3"`
+	Var4 string   `env_var:"NS_VAR_4"`
+}
+
+func TestNullSentinel(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "NULL",
+			env: "NS_VAR_1",
+		},
+		{
+			a:   "NULL",
+			env: "NS_VAR_2",
+		},
+		{
+			a:   "NULL",
+			env: "NS_VAR_3",
+		},
+	}
+	testInt := 1
+	testStruct := TestNullSentinelStruct{Var1: defaultString, Var2: []string{defaultString}, Var3: &testInt, Var4: defaultString}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithNullSentinel("NULL")); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestNullSentinelStruct{Var4: defaultString}}, {b: testInt, want: 1}})
+	testStruct = TestNullSentinelStruct{Var1: defaultString}
+	if err := loadTestStruct(t, testCasesA[:1], &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: "NULL"}})
+}
+
+// testYamlUnmarshal supports flat 'key: value' documents, which is sufficient for testing.
+func testYamlUnmarshal(data []byte, v interface{}) error {
+	m := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid line '%s'", line)
+		}
+		m[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+type TestUnmarshalerStruct struct {
+	Var1 TestSubStruct  `env_var:"UM_VAR_1" env_parser:"yaml"`
+	Var2 *TestSubStruct `env_var:"UM_VAR_1" env_parser:"yaml"`
+}
+
+func TestWithUnmarshaler(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "var: test\nvarptr: " + defaultString + "\n",
+			env: "UM_VAR_1",
+		},
+	}
+	testStruct := TestUnmarshalerStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithUnmarshaler("yaml", testYamlUnmarshal)); err != nil {
+		t.Fatal(err)
+	}
+	want := TestSubStruct{Var: testString, VarPtr: &defaultString}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: want}, {b: *testStruct.Var2, want: want}})
+	testCasesA[0].a = "invalid"
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithUnmarshaler("yaml", testYamlUnmarshal)); err == nil {
+		t.Error("missing error")
+	}
+}
+
+type TestFieldNamePrefixSubStruct struct {
+	Host string `env_var:"HOST"`
+}
+
+type TestFieldNamePrefixStruct struct {
+	Database   TestFieldNamePrefixSubStruct
+	CacheDB    *TestFieldNamePrefixSubStruct
+	Queue      TestFieldNamePrefixSubStruct `env_prefix:"MQ"`
+	TestCommon *TestCommon
+}
+
+func TestFieldNamePrefix(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "db",
+			env: "DATABASE_HOST",
+		},
+		{
+			a:   "cache",
+			env: "CACHE_DB_HOST",
+		},
+		{
+			a:   "mq",
+			env: "MQ_HOST",
+		},
+		{
+			a:   "queue",
+			env: "QUEUE_HOST",
+		},
+		{
+			a:   "common",
+			env: "TEST_COMMON_COMMON_NAME",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestFieldNamePrefixStruct{}
+	if err := LoadEnv(&testStruct, WithFieldNamePrefix(true)); err != nil {
+		t.Fatal(err)
+	}
+	if testStruct.CacheDB == nil || testStruct.TestCommon == nil {
+		t.Fatalf("nil pointer in %+v", testStruct)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Database.Host,
+			want: "db",
+		},
+		{
+			b:    testStruct.CacheDB.Host,
+			want: "cache",
+		},
+		{
+			b:    testStruct.Queue.Host,
+			want: "mq",
+		},
+		{
+			b:    testStruct.TestCommon.Name,
+			want: "common",
+		},
+	}
+	testValues(t, testCasesB)
+	testStruct = TestFieldNamePrefixStruct{}
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Database.Host, want: ""}})
+	for name, want := range map[string]string{"DBHostName": "DB_HOST_NAME", "Database": "DATABASE", "HTTPServer": "HTTP_SERVER", "Api2Key": "API2_KEY"} {
+		if got := upperSnake(name); got != want {
+			t.Errorf("upperSnake(%s) = %s; want %s", name, got, want)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	kwParsers := map[string]Parser{
+		"flaky": func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+			calls++
+			if calls < 3 {
+				return nil, errors.New("backend unavailable")
+			}
+			return val, nil
+		},
+		"broken": func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+			calls++
+			return nil, errors.New("backend unavailable")
+		},
+	}
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "RETRY_VAR_1",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := struct {
+		Var1 string `env_var:"RETRY_VAR_1" env_parser:"flaky"`
+	}{}
+	if err := LoadEnvUserParser(&testStruct, kwParsers, nil, nil, WithRetry(2, time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: testString}, {b: calls, want: 3}})
+	calls = 0
+	if err := LoadEnvUserParser(&testStruct, kwParsers, nil, nil, WithRetry(1, time.Millisecond)); err == nil || !strings.Contains(err.Error(), "backend unavailable") {
+		t.Errorf("err = %v; want backend unavailable", err)
+	}
+	testValues(t, []TestCaseB{{b: calls, want: 2}})
+	calls = 0
+	fallbackStruct := struct {
+		Var1 string  `env_var:"RETRY_VAR_1" env_parser:"broken"`
+		Var2 *string `env_var:"RETRY_VAR_1" env_parser:"broken"`
+	}{Var1: defaultString}
+	if err := LoadEnvUserParser(&fallbackStruct, kwParsers, nil, nil, WithRetry(2, time.Millisecond), WithParseFallback(true)); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: fallbackStruct.Var1, want: defaultString}, {b: fallbackStruct.Var2 == nil, want: true}, {b: calls, want: 6}})
+}
+
+type TestFinalizerStruct struct {
+	Host string `env_var:"FINALIZER_VAR_1"`
+	Port int    `env_var:"FINALIZER_VAR_2"`
+	Sub  TestSubStruct
+	Addr string
+}
+
+func TestFinalizer(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "localhost",
+			env: "FINALIZER_VAR_1",
+		},
+		{
+			a:   "8080",
+			env: "FINALIZER_VAR_2",
+		},
+		{
+			a:   testString,
+			env: "SUB_VAR",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	var calls []string
+	finalizer := func(itf interface{}) error {
+		s := itf.(*TestFinalizerStruct)
+		calls = append(calls, s.Sub.Var)
+		s.Addr = fmt.Sprintf("%s:%d", s.Host, s.Port)
+		return nil
+	}
+	testStruct := TestFinalizerStruct{}
+	if err := LoadEnv(&testStruct, WithFinalizer(finalizer)); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Addr, want: "localhost:8080"}, {b: calls, want: []string{testString}}})
+	errFinalizer := errors.New("port reserved")
+	err := LoadEnv(&TestFinalizerStruct{}, WithFinalizer(func(itf interface{}) error {
+		return errFinalizer
+	}), WithFinalizer(finalizer))
+	if !errors.Is(err, errFinalizer) {
+		t.Errorf("err = %v; want %v", err, errFinalizer)
+	}
+	testValues(t, []TestCaseB{{b: len(calls), want: 1}})
+	testStruct = TestFinalizerStruct{}
+	if err := ValidateEnv(&testStruct, WithFinalizer(finalizer)); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestFinalizerStruct{}}, {b: len(calls), want: 1}})
+}
+
+func TestDefaultsFile(t *testing.T) {
+	path := writeTestFile(t, "DEFAULTS_VAR_1=file\nDEFAULTS_VAR_2=file\n")
+	testCasesA := []TestCaseA{
+		{
+			a:   "env",
+			env: "DEFAULTS_VAR_2",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := struct {
+		Var1 string `env_var:"DEFAULTS_VAR_1"`
+		Var2 string `env_var:"DEFAULTS_VAR_2"`
+	}{}
+	if err := LoadEnv(&testStruct, WithDefaultsFile(path)); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: "file"}, {b: testStruct.Var2, want: "env"}})
+	if _, ok := os.LookupEnv("DEFAULTS_VAR_1"); ok {
+		t.Error("environment modified")
+	}
+	if err := LoadEnv(&testStruct, WithDefaultsFile(filepath.Join(t.TempDir(), "missing"))); err == nil {
+		t.Error("missing error")
+	}
+}
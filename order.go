@@ -0,0 +1,71 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var stringSliceType = reflect.TypeOf([]string{})
+
+// keyOrder returns the keys of a JSON object in order of their first appearance.
+func keyOrder(val string) ([]string, error) {
+	d := json.NewDecoder(strings.NewReader(val))
+	if tok, err := d.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("'%v' provided but json object required", tok)
+	}
+	keys := []string{}
+	seen := make(map[string]bool)
+	for d.More() {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := tok.(string)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+		var raw json.RawMessage
+		if err = d.Decode(&raw); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// setKeyOrder sets the []string field named by the env_key_order tag to the key order of the JSON object val.
+func setKeyOrder(v reflect.Value, f *field, envVar, val string) error {
+	st, ok := v.Type().FieldByName(f.keyOrder)
+	if !ok || st.Type != stringSliceType {
+		return &LoadError{Field: f.st.Name, EnvVar: envVar, Err: fmt.Errorf("key order field '%s' of type '%s' required", f.keyOrder, stringSliceType)}
+	}
+	if st.PkgPath != "" {
+		return &LoadError{Field: f.st.Name, EnvVar: envVar, Err: fmt.Errorf("key order field '%s' is unexported", f.keyOrder)}
+	}
+	keys, err := keyOrder(val)
+	if err != nil {
+		return &LoadError{Field: f.st.Name, EnvVar: envVar, Err: err}
+	}
+	v.FieldByIndex(st.Index).Set(reflect.ValueOf(keys))
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"strings"
+	"testing"
+)
+
+type TestKeyOrderStruct struct {
+	Var1     map[string]int `env_var:"ORDER_VAR_1" env_key_order:"Var1Keys"`
+	Var1Keys []string
+}
+
+func TestKeyOrder(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"zeta": 1, "alpha": {"x": [1, 2]}, "mid": 3, "alpha": 4}`,
+			env: "ORDER_VAR_1",
+		},
+	}
+	testStruct := struct {
+		Var1     map[string]interface{} `env_var:"ORDER_VAR_1" env_key_order:"Var1Keys"`
+		Var1Keys []string
+	}{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1Keys, want: []string{"zeta", "alpha", "mid"}}, {b: len(testStruct.Var1), want: 3}})
+	testCasesA[0].a = `{"b": 2, "a": 1, "c": 3}`
+	orderStruct := TestKeyOrderStruct{}
+	if err := loadTestStruct(t, testCasesA, &orderStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: orderStruct.Var1Keys, want: []string{"b", "a", "c"}}, {b: orderStruct.Var1, want: map[string]int{"a": 1, "b": 2, "c": 3}}})
+	err := loadTestStruct(t, testCasesA, &struct {
+		Var1     map[string]int `env_var:"ORDER_VAR_1" env_key_order:"Var1Keys"`
+		Var1Keys []int
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "key order field 'Var1Keys' of type '[]string' required") {
+		t.Errorf("err = %v; want key order field error", err)
+	}
+	err = loadTestStruct(t, testCasesA, &struct {
+		Var1     map[string]int `env_var:"ORDER_VAR_1" env_key_order:"var1Keys"`
+		var1Keys []string
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "key order field 'var1Keys' is unexported") {
+		t.Errorf("err = %v; want unexported key order field error", err)
+	}
+	testCasesA[0].a = "{"
+	orderStruct = TestKeyOrderStruct{Var1: map[string]int{"a": 1}}
+	if err := loadTestStruct(t, testCasesA, &orderStruct, WithParseFallback(true)); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: orderStruct, want: TestKeyOrderStruct{Var1: map[string]int{"a": 1}}}})
+}
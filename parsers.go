@@ -0,0 +1,607 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const sepKw = "sep"
+const defaultSep = ","
+const posixKw = "posix"
+const kvSepKw = "kvsep"
+const defaultKvSep = "="
+const valSepKw = "valsep"
+const defaultValSep = "|"
+const enumKw = "enum"
+const enumSep = "|"
+const patternKw = "pattern"
+const absKw = "abs"
+const mapKw = "map"
+const mapSep = ":"
+const jsonPrefix = "json:"
+const b64Prefix = "b64:"
+const filePrefix = "file:"
+
+func parseElem(t reflect.Type, val string, params []string, kwParams map[string]string) (reflect.Value, error) {
+	if t.Kind() == reflect.Ptr {
+		elem, err := parseElem(t.Elem(), val, params, kwParams)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+	p, ok := builtinTypeParsers[t]
+	if !ok {
+		if p, ok = parsers[t.Kind()]; !ok {
+			return reflect.Value{}, fmt.Errorf("no parser for '%s'", t)
+		}
+	}
+	itf, err := p(t, val, params, kwParams)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	v := reflect.Indirect(reflect.ValueOf(itf))
+	if v.Type() != t {
+		v = v.Convert(t)
+	}
+	return v, checkRegisteredEnum(v)
+}
+
+// RequireParams returns an error if not exactly n positional params are given, for use in custom parsers.
+func RequireParams(params []string, n int) error {
+	if len(params) != n {
+		return fmt.Errorf("%d positional params required but %d given", n, len(params))
+	}
+	return nil
+}
+
+func getSep(kwParams map[string]string) string {
+	if sep, ok := kwParams[sepKw]; ok && sep != "" {
+		return sep
+	}
+	return defaultSep
+}
+
+func checkEnum(val string, kwParams map[string]string) error {
+	enum, ok := kwParams[enumKw]
+	if !ok {
+		return nil
+	}
+	for _, allowed := range strings.Split(enum, enumSep) {
+		if val == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("'%s' not in '%s'", val, enum)
+}
+
+func getPattern(kwParams map[string]string) (*regexp.Regexp, error) {
+	p, ok := kwParams[patternKw]
+	if !ok {
+		return nil, nil
+	}
+	re, err := regexp.Compile("^(?:" + p + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %w", p, err)
+	}
+	return re, nil
+}
+
+var listParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if t.Kind() == reflect.Array {
+		return listArray(t, val, params, kwParams)
+	}
+	if t.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("'%s' provided but '%s' required", t.Kind(), reflect.Slice)
+	}
+	return parseList(t, val, params, kwParams)
+}
+
+func parseList(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	slice := reflect.MakeSlice(t, 0, 0)
+	if val == "" {
+		return slice.Interface(), nil
+	}
+	re, err := getPattern(kwParams)
+	if err != nil {
+		return nil, err
+	}
+	for i, part := range strings.Split(val, getSep(kwParams)) {
+		part = strings.TrimSpace(part)
+		if err := checkEnum(part, kwParams); err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		if re != nil && !re.MatchString(part) {
+			return nil, fmt.Errorf("element %d: '%s' doesn't match '%s'", i, part, kwParams[patternKw])
+		}
+		elem, err := parseElem(t.Elem(), part, params, kwParams)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	return slice.Interface(), nil
+}
+
+// listArray parses a list into an array, the number of elements must match the array length.
+func listArray(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	itf, err := parseList(reflect.SliceOf(t.Elem()), val, params, kwParams)
+	if err != nil {
+		return nil, err
+	}
+	slice := reflect.ValueOf(itf)
+	if slice.Len() != t.Len() {
+		return nil, fmt.Errorf("%d elements provided but %d required", slice.Len(), t.Len())
+	}
+	array := reflect.New(t).Elem()
+	reflect.Copy(array, slice)
+	return array.Interface(), nil
+}
+
+var gzJsonParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	b, err := base64.StdEncoding.DecodeString(val)
+	if err != nil {
+		return nil, fmt.Errorf("base64 decoding failed: %w", err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("gzip decompression failed: %w", err)
+	}
+	defer r.Close()
+	b, err = io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("gzip decompression failed: %w", err)
+	}
+	itf, err := jsonParser(t, string(b), params, kwParams)
+	if err != nil {
+		return nil, fmt.Errorf("json decoding failed: %w", err)
+	}
+	return itf, nil
+}
+
+var linesParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if t.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("'%s' provided but '%s' required", t.Kind(), reflect.Slice)
+	}
+	slice := reflect.MakeSlice(t, 0, 0)
+	for i, line := range strings.Split(val, "\n") {
+		if line = strings.TrimSuffix(line, "\r"); line == "" {
+			continue
+		}
+		elem, err := parseElem(t.Elem(), line, params, kwParams)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	return slice.Interface(), nil
+}
+
+var jsonLinesParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if t.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("'%s' provided but '%s' required", t.Kind(), reflect.Slice)
+	}
+	slice := reflect.MakeSlice(t, 0, 0)
+	for i, line := range strings.Split(val, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		itf, err := jsonParser(t.Elem(), line, params, kwParams)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		slice = reflect.Append(slice, reflect.Indirect(reflect.ValueOf(itf)))
+	}
+	return slice.Interface(), nil
+}
+
+// parseValues splits a kvmap value by valsep (default '|') into a slice.
+func parseValues(t reflect.Type, val string, params []string, kwParams map[string]string) (reflect.Value, error) {
+	valSep := defaultValSep
+	if s, ok := kwParams[valSepKw]; ok && s != "" {
+		valSep = s
+	}
+	slice := reflect.MakeSlice(t, 0, 0)
+	if val == "" {
+		return slice, nil
+	}
+	for i, part := range strings.Split(val, valSep) {
+		elem, err := parseElem(t.Elem(), strings.TrimSpace(part), params, kwParams)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("element %d: %w", i, err)
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	return slice, nil
+}
+
+var kvMapParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if t.Kind() != reflect.Map {
+		return nil, fmt.Errorf("'%s' provided but '%s' required", t.Kind(), reflect.Map)
+	}
+	kvSep := defaultKvSep
+	if s, ok := kwParams[kvSepKw]; ok && s != "" {
+		kvSep = s
+	}
+	m := reflect.MakeMap(t)
+	if val == "" {
+		return m.Interface(), nil
+	}
+	for _, part := range strings.Split(val, getSep(kwParams)) {
+		kv := strings.SplitN(part, kvSep, 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid key value pair '%s'", part)
+		}
+		key, err := parseElem(t.Key(), strings.TrimSpace(kv[0]), params, kwParams)
+		if err != nil {
+			return nil, fmt.Errorf("key '%s': %w", kv[0], err)
+		}
+		var elem reflect.Value
+		if t.Elem().Kind() == reflect.Slice {
+			elem, err = parseValues(t.Elem(), strings.TrimSpace(kv[1]), params, kwParams)
+		} else {
+			elem, err = parseElem(t.Elem(), strings.TrimSpace(kv[1]), params, kwParams)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("value of key '%s': %w", kv[0], err)
+		}
+		m.SetMapIndex(key, elem)
+	}
+	return m.Interface(), nil
+}
+
+var setParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if t.Kind() != reflect.Map {
+		return nil, fmt.Errorf("'%s' provided but '%s' required", t.Kind(), reflect.Map)
+	}
+	m := reflect.MakeMap(t)
+	if val == "" {
+		return m.Interface(), nil
+	}
+	for i, part := range strings.Split(val, getSep(kwParams)) {
+		key, err := parseElem(t.Key(), strings.TrimSpace(part), params, kwParams)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		m.SetMapIndex(key, reflect.Zero(t.Elem()))
+	}
+	return m.Interface(), nil
+}
+
+func getMapping(params []string, kwParams map[string]string) (map[string]string, error) {
+	entries, ok := kwParams[mapKw]
+	if !ok {
+		return nil, fmt.Errorf("missing '%s' parameter", mapKw)
+	}
+	mapping := make(map[string]string)
+	for _, entry := range append([]string{entries}, params...) {
+		kv := strings.SplitN(entry, mapSep, 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid mapping '%s'", entry)
+		}
+		mapping[kv[0]] = kv[1]
+	}
+	return mapping, nil
+}
+
+var mappingParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	mapping, err := getMapping(params, kwParams)
+	if err != nil {
+		return nil, err
+	}
+	mapped, ok := mapping[val]
+	if !ok {
+		return nil, fmt.Errorf("'%s' not mapped", val)
+	}
+	return parseDefault(t, mapped, nil, nil)
+}
+
+var hostPortParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("'%s' provided but '%s' required", t.Kind(), reflect.Struct)
+	}
+	host, port, err := net.SplitHostPort(val)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.New(t).Elem()
+	hostField := v.FieldByName("Host")
+	portField := v.FieldByName("Port")
+	if !hostField.IsValid() || !portField.IsValid() {
+		return nil, fmt.Errorf("'%s' requires fields 'Host' and 'Port'", t)
+	}
+	if hostField.Kind() != reflect.String {
+		return nil, fmt.Errorf("'%s' provided but '%s' required", hostField.Kind(), reflect.String)
+	}
+	hostField.SetString(host)
+	p, err := parseElem(portField.Type(), port, params, kwParams)
+	if err != nil {
+		return nil, err
+	}
+	portField.Set(p)
+	return v.Interface(), nil
+}
+
+var regexpParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if posix, _ := strconv.ParseBool(kwParams[posixKw]); posix {
+		return regexp.CompilePOSIX(val)
+	}
+	return regexp.Compile(val)
+}
+
+func splitShellWords(val string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	var inWord, escaped bool
+	var quote rune
+	for _, r := range val {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("unterminated escape in '%s'", val)
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unbalanced quote in '%s'", val)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+var shellWordsParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.String {
+		return nil, fmt.Errorf("'%s' provided but '[]string' required", t)
+	}
+	words, err := splitShellWords(val)
+	if err != nil {
+		return nil, err
+	}
+	slice := reflect.MakeSlice(t, 0, len(words))
+	for _, w := range words {
+		slice = reflect.Append(slice, reflect.ValueOf(w).Convert(t.Elem()))
+	}
+	return slice.Interface(), nil
+}
+
+func parseDefault(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	v, err := parseElem(t, val, params, kwParams)
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+var autoParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(val, jsonPrefix):
+		return jsonParser(t, strings.TrimPrefix(val, jsonPrefix), params, kwParams)
+	case strings.HasPrefix(val, b64Prefix):
+		b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(val, b64Prefix))
+		if err != nil {
+			return nil, fmt.Errorf("base64 decoding failed: %w", err)
+		}
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return reflect.ValueOf(b).Convert(t).Interface(), nil
+		}
+		return parseDefault(t, string(b), params, kwParams)
+	case strings.HasPrefix(val, filePrefix):
+		b, err := os.ReadFile(strings.TrimPrefix(val, filePrefix))
+		if err != nil {
+			return nil, err
+		}
+		return parseDefault(t, strings.TrimRight(string(b), "\r\n"), params, kwParams)
+	default:
+		return parseDefault(t, val, params, kwParams)
+	}
+}
+
+var dirParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if t.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("'%s' provided but '%s' required", t.Kind(), reflect.Slice)
+	}
+	entries, err := os.ReadDir(val)
+	if err != nil {
+		return nil, err
+	}
+	slice := reflect.MakeSlice(t, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(val, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		elem, err := parseElem(t.Elem(), strings.TrimRight(string(b), "\r\n"), params, kwParams)
+		if err != nil {
+			return nil, fmt.Errorf("file '%s': %w", entry.Name(), err)
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	return slice.Interface(), nil
+}
+
+var boolIntParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if t.Kind() < reflect.Int || t.Kind() > reflect.Uintptr {
+		return nil, fmt.Errorf("'%s' provided but integer required", t.Kind())
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return nil, err
+	}
+	i := 0
+	if b {
+		i = 1
+	}
+	return reflect.ValueOf(i).Convert(t).Interface(), nil
+}
+
+var byteSizeUnits = map[string]uint64{
+	"":    1,
+	"B":   1,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"PB":  1e15,
+	"EB":  1e18,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+	"PiB": 1 << 50,
+	"EiB": 1 << 60,
+}
+
+var byteSizeParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if t.Kind() < reflect.Int || t.Kind() > reflect.Uintptr {
+		return nil, fmt.Errorf("'%s' provided but integer required", t.Kind())
+	}
+	i := strings.IndexFunc(val, func(r rune) bool { return r < '0' || r > '9' })
+	if i < 0 {
+		i = len(val)
+	}
+	unit, ok := byteSizeUnits[strings.TrimSpace(val[i:])]
+	if !ok {
+		return nil, fmt.Errorf("unknown unit in '%s'", val)
+	}
+	n, err := strconv.ParseUint(val[:i], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if n > math.MaxUint64/unit {
+		return nil, strconv.ErrRange
+	}
+	n *= unit
+	v := reflect.New(t).Elem()
+	if t.Kind() >= reflect.Uint {
+		if v.OverflowUint(n) {
+			return nil, strconv.ErrRange
+		}
+		v.SetUint(n)
+	} else {
+		if n > math.MaxInt64 || v.OverflowInt(int64(n)) {
+			return nil, strconv.ErrRange
+		}
+		v.SetInt(int64(n))
+	}
+	return v.Interface(), nil
+}
+
+var percentParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if t.Kind() != reflect.Float32 && t.Kind() != reflect.Float64 {
+		return nil, fmt.Errorf("'%s' provided but float required", t.Kind())
+	}
+	num, isPercent := strings.CutSuffix(val, "%")
+	if strict, _ := strconv.ParseBool(kwParams[strictKw]); strict && !isPercent {
+		return nil, fmt.Errorf("missing '%%' in '%s'", val)
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(num), bitSizeMap[t.Kind()])
+	if err != nil {
+		return nil, err
+	}
+	if isPercent {
+		f /= 100
+	}
+	return reflect.ValueOf(f).Convert(t).Interface(), nil
+}
+
+var pathParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if t.Kind() != reflect.String {
+		return nil, fmt.Errorf("'%s' provided but '%s' required", t.Kind(), reflect.String)
+	}
+	if val == "~" || strings.HasPrefix(val, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		val = home + val[1:]
+	}
+	val = filepath.Clean(os.ExpandEnv(val))
+	if abs, _ := strconv.ParseBool(kwParams[absKw]); abs {
+		var err error
+		if val, err = filepath.Abs(val); err != nil {
+			return nil, err
+		}
+	}
+	return reflect.ValueOf(val).Convert(t).Interface(), nil
+}
+
+var builtinKwParsers = map[string]Parser{
+	"json":       jsonParser,
+	"gzjson":     gzJsonParser,
+	"jsonl":      jsonLinesParser,
+	"lines":      linesParser,
+	"list":       listParser,
+	"kvmap":      kvMapParser,
+	"set":        setParser,
+	"mapping":    mappingParser,
+	"semver":     semVerParser,
+	"netaddr":    netAddrParser,
+	"dir":        dirParser,
+	"path":       pathParser,
+	"boolint":    boolIntParser,
+	"bytesize":   byteSizeParser,
+	"percent":    percentParser,
+	"hostport":   hostPortParser,
+	"shellwords": shellWordsParser,
+	"auto":       autoParser,
+}
@@ -0,0 +1,1054 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type TestGzJsonStruct struct {
+	Var1 []TestItem `env_var:"GZ_VAR_1" env_parser:"gzjson"`
+}
+
+func TestGzJsonParser(t *testing.T) {
+	testStructSlice := []TestItem{{Var: testString}, {Var: defaultString}}
+	b, err := json.Marshal(testStructSlice)
+	if err != nil {
+		panic(err)
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err = w.Write(b); err != nil {
+		panic(err)
+	}
+	if err = w.Close(); err != nil {
+		panic(err)
+	}
+	testCasesA := []TestCaseA{
+		{
+			a:   base64.StdEncoding.EncodeToString(buf.Bytes()),
+			env: "GZ_VAR_1",
+		},
+	}
+	testStruct := TestGzJsonStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: testStructSlice,
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestGzJsonParserCorrupt(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "not base64!",
+			env: "GZ_VAR_1",
+		},
+	}
+	testStruct := TestGzJsonStruct{}
+	var b64Err base64.CorruptInputError
+	if err := loadTestStruct(t, testCasesA, &testStruct); !errors.As(err, &b64Err) {
+		t.Errorf("err = %v; want %T", err, b64Err)
+	}
+	testCasesA[0].a = base64.StdEncoding.EncodeToString([]byte("not gzip compressed"))
+	if err := loadTestStruct(t, testCasesA, &testStruct); !errors.Is(err, gzip.ErrHeader) {
+		t.Errorf("err = %v; want %v", err, gzip.ErrHeader)
+	}
+}
+
+type TestJsonString string
+
+type TestJsonScalarStruct struct {
+	Var1 string         `env_var:"JSON_VAR_1" env_parser:"json"`
+	Var2 TestJsonString `env_var:"JSON_VAR_1" env_parser:"json"`
+	Var3 *string        `env_var:"JSON_VAR_1" env_parser:"json"`
+	Var4 string         `env_var:"JSON_VAR_1"`
+}
+
+func TestJsonParserScalar(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `"hello \"world\""`,
+			env: "JSON_VAR_1",
+		},
+	}
+	testStruct := TestJsonScalarStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	if testStruct.Var3 == nil {
+		t.Fatal("Var3 not set")
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: `hello "world"`,
+		},
+		{
+			b:    testStruct.Var2,
+			want: TestJsonString(`hello "world"`),
+		},
+		{
+			b:    *testStruct.Var3,
+			want: `hello "world"`,
+		},
+		{
+			b:    testStruct.Var4,
+			want: `"hello \"world\""`,
+		},
+	}
+	testValues(t, testCasesB)
+	testCasesA[0].a = "hello"
+	var syntaxErr *json.SyntaxError
+	if err := loadTestStruct(t, testCasesA, &TestJsonScalarStruct{}); !errors.As(err, &syntaxErr) {
+		t.Errorf("err = %v; want %T", err, syntaxErr)
+	}
+}
+
+type TestJsonLinesStruct struct {
+	Var1 []TestItem  `env_var:"JSONL_VAR_1" env_parser:"jsonl"`
+	Var2 []*TestItem `env_var:"JSONL_VAR_1" env_parser:"jsonl"`
+}
+
+func TestJsonLinesParser(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "{\"Var\":\"a\"}\n{\"Var\":\"b\"}\n{\"Var\":\"c\"}",
+			env: "JSONL_VAR_1",
+		},
+	}
+	testStruct := TestJsonLinesStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	want := []TestItem{{Var: "a"}, {Var: "b"}, {Var: "c"}}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: want}, {b: testStruct.Var2, want: []*TestItem{&want[0], &want[1], &want[2]}}})
+	testCasesA[0].a = "{\"Var\":\"a\"}\r\n\n  \n{\"Var\":\"b\"}\n"
+	testStruct = TestJsonLinesStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: want[:2]}})
+	testCasesA[0].a = "{\"Var\":\"a\"}\n\n{\"Var\":}"
+	err := loadTestStruct(t, testCasesA, &TestJsonLinesStruct{})
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) || !strings.Contains(err.Error(), "line 3:") {
+		t.Errorf("err = %v; want syntax error in line 3", err)
+	}
+	sparseStruct := struct {
+		Var1 [][]int `env_var:"JSONL_VAR_1" env_parser:"jsonl" env_params:"sparse=true"`
+	}{}
+	testCasesA[0].a = "[1,2]\n{\"2\":3}"
+	if err := loadTestStruct(t, testCasesA, &sparseStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: sparseStruct.Var1, want: [][]int{{1, 2}, {0, 0, 3}}}})
+}
+
+type TestListStruct struct {
+	Var1 []string `env_var:"LIST_VAR_1" env_parser:"list"`
+	Var2 []int    `env_var:"LIST_VAR_2" env_parser:"list" env_params:"sep=|"`
+	Var3 []byte   `env_var:"LIST_VAR_3" env_parser:"list"`
+}
+
+func TestListParser(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "a, b,c",
+			env: "LIST_VAR_1",
+		},
+		{
+			a:   "1|2",
+			env: "LIST_VAR_2",
+		},
+		{
+			a:   "12,34,255",
+			env: "LIST_VAR_3",
+		},
+	}
+	testStruct := TestListStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: []string{"a", "b", "c"},
+		},
+		{
+			b:    testStruct.Var2,
+			want: []int{1, 2},
+		},
+		{
+			b:    testStruct.Var3,
+			want: []byte{12, 34, 255},
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestListParserBytes(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "12,256",
+			env: "LIST_VAR_3",
+		},
+	}
+	testStruct := TestListStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("err = %v; want %v", err, strconv.ErrRange)
+	}
+	testCasesA[0].a = "12,a"
+	if err := loadTestStruct(t, testCasesA, &testStruct); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("err = %v; want %v", err, strconv.ErrSyntax)
+	}
+}
+
+type TestListArrayStruct struct {
+	Var1 [6]float64 `env_var:"LIST_VAR_1" env_parser:"list"`
+}
+
+func TestListParserArray(t *testing.T) {
+	testStruct := TestListArrayStruct{}
+	if err := loadTestStruct(t, []TestCaseA{{a: "1.0,2.5, -3,4e2,0,6.25", env: "LIST_VAR_1"}}, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: [6]float64{1, 2.5, -3, 400, 0, 6.25}}})
+	err := loadTestStruct(t, []TestCaseA{{a: "1,2,3", env: "LIST_VAR_1"}}, &TestListArrayStruct{})
+	if err == nil || !strings.Contains(err.Error(), "3 elements provided but 6 required") {
+		t.Errorf("err = %v; want length error", err)
+	}
+	err = loadTestStruct(t, []TestCaseA{{a: "1,2,x,4,5,6", env: "LIST_VAR_1"}}, &TestListArrayStruct{})
+	if !errors.Is(err, strconv.ErrSyntax) || !strings.Contains(err.Error(), "element 2:") {
+		t.Errorf("err = %v; want element 2 syntax error", err)
+	}
+}
+
+type TestListEnumStruct struct {
+	Var1 []string `env_var:"LIST_VAR_1" env_parser:"list" env_params:"enum=a|b|c"`
+}
+
+func TestListParserEnum(t *testing.T) {
+	testCases := []struct {
+		a    string
+		want []string
+	}{
+		{
+			a:    "a, c,b,a",
+			want: []string{"a", "c", "b", "a"},
+		},
+		{
+			a:    "",
+			want: []string{},
+		},
+	}
+	for _, testCase := range testCases {
+		testStruct := TestListEnumStruct{}
+		if err := loadTestStruct(t, []TestCaseA{{a: testCase.a, env: "LIST_VAR_1"}}, &testStruct); err != nil {
+			t.Error(err)
+		}
+		testValues(t, []TestCaseB{{b: testStruct.Var1, want: testCase.want}})
+	}
+	testStruct := TestListEnumStruct{}
+	err := loadTestStruct(t, []TestCaseA{{a: "a,d,e", env: "LIST_VAR_1"}}, &testStruct)
+	if err == nil || !strings.Contains(err.Error(), "element 1: 'd' not in 'a|b|c'") {
+		t.Errorf("err = %v; want element 1 error", err)
+	}
+}
+
+type TestListPatternStruct struct {
+	Var1 []string `env_var:"LIST_VAR_1" env_parser:"list" env_params:"pattern=[a-z]+-[0-9]+"`
+}
+
+func TestListParserPattern(t *testing.T) {
+	testStruct := TestListPatternStruct{}
+	if err := loadTestStruct(t, []TestCaseA{{a: "a-1, bc-23", env: "LIST_VAR_1"}}, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: []string{"a-1", "bc-23"}}})
+	testStruct = TestListPatternStruct{}
+	err := loadTestStruct(t, []TestCaseA{{a: "a-1,b-2x,c-3", env: "LIST_VAR_1"}}, &testStruct)
+	if err == nil || !strings.Contains(err.Error(), "element 1: 'b-2x' doesn't match '[a-z]+-[0-9]+'") {
+		t.Errorf("err = %v; want element 1 error", err)
+	}
+	err = loadTestStruct(t, []TestCaseA{{a: "a-1", env: "LIST_VAR_1"}}, &struct {
+		Var1 []string `env_var:"LIST_VAR_1" env_parser:"list" env_params:"pattern=[a-z"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Errorf("err = %v; want invalid pattern error", err)
+	}
+}
+
+type TestMapKeyStruct struct {
+	Var1 map[int]string   `env_var:"MAP_VAR_1"`
+	Var2 map[int64]bool   `env_var:"MAP_VAR_2"`
+	Var3 map[int]string   `env_var:"MAP_VAR_3" env_parser:"kvmap"`
+	Var4 map[uint8]bool   `env_var:"MAP_VAR_4" env_parser:"kvmap" env_params:"sep=|;kvsep=:"`
+	Var5 map[string]int64 `env_var:"MAP_VAR_5" env_parser:"kvmap"`
+}
+
+func TestLoadMapKeys(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"1":"a","2":"b"}`,
+			env: "MAP_VAR_1",
+		},
+		{
+			a:   `{"-1":true}`,
+			env: "MAP_VAR_2",
+		},
+		{
+			a:   "1=a,2=b",
+			env: "MAP_VAR_3",
+		},
+		{
+			a:   "1:true|2:false",
+			env: "MAP_VAR_4",
+		},
+		{
+			a:   "a=1, b=2",
+			env: "MAP_VAR_5",
+		},
+	}
+	testStruct := TestMapKeyStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: map[int]string{1: "a", 2: "b"},
+		},
+		{
+			b:    testStruct.Var2,
+			want: map[int64]bool{-1: true},
+		},
+		{
+			b:    testStruct.Var3,
+			want: map[int]string{1: "a", 2: "b"},
+		},
+		{
+			b:    testStruct.Var4,
+			want: map[uint8]bool{1: true, 2: false},
+		},
+		{
+			b:    testStruct.Var5,
+			want: map[string]int64{"a": 1, "b": 2},
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestLoadMapKeysError(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"a":"b"}`,
+			env: "MAP_VAR_1",
+		},
+	}
+	testStruct := TestMapKeyStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err == nil {
+		t.Error("missing error")
+	}
+	testCasesA = []TestCaseA{
+		{
+			a:   "a=b",
+			env: "MAP_VAR_3",
+		},
+	}
+	if err := loadTestStruct(t, testCasesA, &testStruct); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("err = %v; want %v", err, strconv.ErrSyntax)
+	}
+	testCasesA = []TestCaseA{
+		{
+			a:   "256:true",
+			env: "MAP_VAR_4",
+		},
+	}
+	if err := loadTestStruct(t, testCasesA, &testStruct); !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("err = %v; want %v", err, strconv.ErrRange)
+	}
+}
+
+type TestMultiMapStruct struct {
+	Var1 map[string][]string `env_var:"MM_VAR_1"`
+	Var2 map[string][]string `env_var:"MM_VAR_2" env_parser:"kvmap"`
+	Var3 map[string][]int    `env_var:"MM_VAR_3" env_parser:"kvmap" env_params:"valsep=+"`
+}
+
+func TestLoadMultiValueMap(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   `{"Accept":["a","b"],"Host":["h"]}`,
+			env: "MM_VAR_1",
+		},
+		{
+			a:   "Accept=a|b, Host=h,Empty=",
+			env: "MM_VAR_2",
+		},
+		{
+			a:   "a=1+2,b=3",
+			env: "MM_VAR_3",
+		},
+	}
+	testStruct := TestMultiMapStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: map[string][]string{"Accept": {"a", "b"}, "Host": {"h"}},
+		},
+		{
+			b:    testStruct.Var2,
+			want: map[string][]string{"Accept": {"a", "b"}, "Host": {"h"}, "Empty": {}},
+		},
+		{
+			b:    testStruct.Var3,
+			want: map[string][]int{"a": {1, 2}, "b": {3}},
+		},
+	}
+	testValues(t, testCasesB)
+	if err := loadTestStruct(t, []TestCaseA{{a: "a=1+x", env: "MM_VAR_3"}}, &testStruct); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("err = %v; want %v", err, strconv.ErrSyntax)
+	}
+}
+
+type TestSetStruct struct {
+	Var1 map[string]struct{} `env_var:"SET_VAR_1" env_parser:"set"`
+	Var2 map[int]struct{}    `env_var:"SET_VAR_2" env_parser:"set"`
+}
+
+func TestSetParser(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "a, b,a",
+			env: "SET_VAR_1",
+		},
+		{
+			a:   "",
+			env: "SET_VAR_2",
+		},
+	}
+	testStruct := TestSetStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: map[string]struct{}{"a": {}, "b": {}},
+		},
+		{
+			b:    testStruct.Var2,
+			want: map[int]struct{}{},
+		},
+	}
+	testValues(t, testCasesB)
+	testCasesA[1].a = "1,a"
+	if err := loadTestStruct(t, testCasesA, &testStruct); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("err = %v; want %v", err, strconv.ErrSyntax)
+	}
+}
+
+type TestLogLevel int8
+
+type TestMappingStruct struct {
+	Var1 TestLogLevel `env_var:"MAPPING_VAR_1" env_parser:"mapping" env_params:"map=debug:0;info:1;warn:2"`
+	Var2 string       `env_var:"MAPPING_VAR_2" env_parser:"mapping" env_params:"map=dev:development;prod:production"`
+}
+
+func TestMappingParser(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "warn",
+			env: "MAPPING_VAR_1",
+		},
+		{
+			a:   "prod",
+			env: "MAPPING_VAR_2",
+		},
+	}
+	testStruct := TestMappingStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: TestLogLevel(2),
+		},
+		{
+			b:    testStruct.Var2,
+			want: "production",
+		},
+	}
+	testValues(t, testCasesB)
+	testCasesA[0].a = "trace"
+	if err := loadTestStruct(t, testCasesA, &testStruct); err == nil || !strings.Contains(err.Error(), "'trace' not mapped") {
+		t.Errorf("err = %v; want not mapped error", err)
+	}
+	testStruct2 := struct {
+		Var1 int `env_var:"MAPPING_VAR_1" env_parser:"mapping" env_params:"map=warn"`
+	}{}
+	if err := loadTestStruct(t, testCasesA, &testStruct2); err == nil {
+		t.Error("missing error")
+	}
+}
+
+type TestEndpoint struct {
+	Host string
+	Port int
+}
+
+type TestHostPortStruct struct {
+	Var1       TestEndpoint  `env_var:"HP_VAR_1" env_parser:"hostport"`
+	Var1NilPtr *TestEndpoint `env_var:"HP_VAR_1" env_parser:"hostport"`
+	Var2       TestEndpoint  `env_var:"HP_VAR_2" env_parser:"hostport"`
+}
+
+func TestHostPortParser(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "localhost:8080",
+			env: "HP_VAR_1",
+		},
+		{
+			a:   "[::1]:80",
+			env: "HP_VAR_2",
+		},
+	}
+	testStruct := TestHostPortStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: TestEndpoint{Host: "localhost", Port: 8080},
+		},
+		{
+			b:    *testStruct.Var1NilPtr,
+			want: TestEndpoint{Host: "localhost", Port: 8080},
+		},
+		{
+			b:    testStruct.Var2,
+			want: TestEndpoint{Host: "::1", Port: 80},
+		},
+	}
+	testValues(t, testCasesB)
+	testCasesA = []TestCaseA{
+		{
+			a:   "localhost",
+			env: "HP_VAR_1",
+		},
+	}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err == nil {
+		t.Error("missing error")
+	}
+}
+
+type TestRegexpStruct struct {
+	Var1 *regexp.Regexp `env_var:"RE_VAR_1"`
+	Var2 *regexp.Regexp `env_var:"RE_VAR_1" env_params:"posix=true"`
+}
+
+func TestLoadRegexp(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "a|ab",
+			env: "RE_VAR_1",
+		},
+	}
+	testStruct := TestRegexpStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1.FindString("ab"),
+			want: "a",
+		},
+		{
+			b:    testStruct.Var2.FindString("ab"),
+			want: "ab",
+		},
+	}
+	testValues(t, testCasesB)
+	testCasesA[0].a = "a("
+	if err := loadTestStruct(t, testCasesA, &testStruct); err == nil {
+		t.Error("missing error")
+	}
+	testCasesA[0].a = `\d`
+	testStruct2 := struct {
+		Var2 *regexp.Regexp `env_var:"RE_VAR_1" env_params:"posix=true"`
+	}{}
+	if err := loadTestStruct(t, testCasesA, &testStruct2); err == nil {
+		t.Error("missing error")
+	}
+}
+
+type TestShellWordsStruct struct {
+	Var1 []string `env_var:"SW_VAR_1" env_parser:"shellwords"`
+}
+
+func TestShellWordsParser(t *testing.T) {
+	testCases := []struct {
+		a    string
+		want []string
+	}{
+		{
+			a:    `--flag "a b" c`,
+			want: []string{"--flag", "a b", "c"},
+		},
+		{
+			a:    `'a b' "c \"d\"" e\ f`,
+			want: []string{"a b", `c "d"`, "e f"},
+		},
+		{
+			a:    `'a\b' "c\d" ""`,
+			want: []string{`a\b`, `c\d`, ""},
+		},
+	}
+	for _, testCase := range testCases {
+		testStruct := TestShellWordsStruct{}
+		if err := loadTestStruct(t, []TestCaseA{{a: testCase.a, env: "SW_VAR_1"}}, &testStruct); err != nil {
+			t.Error(err)
+		}
+		testValues(t, []TestCaseB{{b: testStruct.Var1, want: testCase.want}})
+	}
+	for _, val := range []string{`"a b`, `'a`, `a\`} {
+		testStruct := TestShellWordsStruct{}
+		if err := loadTestStruct(t, []TestCaseA{{a: val, env: "SW_VAR_1"}}, &testStruct); err == nil {
+			t.Errorf("missing error for %s", val)
+		}
+	}
+}
+
+type TestAutoStruct struct {
+	Var1 []int    `env_var:"AUTO_VAR_1" env_parser:"auto"`
+	Var2 string   `env_var:"AUTO_VAR_2" env_parser:"auto"`
+	Var3 []byte   `env_var:"AUTO_VAR_3" env_parser:"auto"`
+	Var4 int      `env_var:"AUTO_VAR_4" env_parser:"auto"`
+	Var5 TestItem `env_var:"AUTO_VAR_5" env_parser:"auto"`
+}
+
+func TestAutoParser(t *testing.T) {
+	path := writeTestFile(t, "42\n")
+	testCasesA := []TestCaseA{
+		{
+			a:   "json:[1,2]",
+			env: "AUTO_VAR_1",
+		},
+		{
+			a:   "b64:" + base64.StdEncoding.EncodeToString([]byte(testString)),
+			env: "AUTO_VAR_2",
+		},
+		{
+			a:   "b64:" + base64.StdEncoding.EncodeToString([]byte{1, 2}),
+			env: "AUTO_VAR_3",
+		},
+		{
+			a:   "file:" + path,
+			env: "AUTO_VAR_4",
+		},
+		{
+			a:   `{"var":"test"}`,
+			env: "AUTO_VAR_5",
+		},
+	}
+	testStruct := TestAutoStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: []int{1, 2},
+		},
+		{
+			b:    testStruct.Var2,
+			want: testString,
+		},
+		{
+			b:    testStruct.Var3,
+			want: []byte{1, 2},
+		},
+		{
+			b:    testStruct.Var4,
+			want: 42,
+		},
+		{
+			b:    testStruct.Var5,
+			want: TestItem{Var: testString},
+		},
+	}
+	testValues(t, testCasesB)
+	for _, testCaseA := range []TestCaseA{{a: "b64:not base64!", env: "AUTO_VAR_2"}, {a: "file:" + path + ".missing", env: "AUTO_VAR_4"}} {
+		if err := loadTestStruct(t, []TestCaseA{testCaseA}, &TestAutoStruct{}); err == nil {
+			t.Errorf("missing error for %s", testCaseA.a)
+		}
+	}
+}
+
+type TestDirStruct struct {
+	Var1 []string `env_var:"DIR_VAR_1" env_parser:"dir"`
+	Var2 []int    `env_var:"DIR_VAR_1" env_parser:"dir"`
+}
+
+func TestDirParser(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"10-c": "3\n", "01-a": "1", "02-b": "2\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			panic(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "05-sub"), 0700); err != nil {
+		panic(err)
+	}
+	testStruct := TestDirStruct{}
+	if err := loadTestStruct(t, []TestCaseA{{a: dir, env: "DIR_VAR_1"}}, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: []string{"1", "2", "3"},
+		},
+		{
+			b:    testStruct.Var2,
+			want: []int{1, 2, 3},
+		},
+	}
+	testValues(t, testCasesB)
+	if err := loadTestStruct(t, []TestCaseA{{a: filepath.Join(dir, "missing"), env: "DIR_VAR_1"}}, &testStruct); err == nil {
+		t.Error("missing error")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "03-x"), []byte("x"), 0600); err != nil {
+		panic(err)
+	}
+	if err := loadTestStruct(t, []TestCaseA{{a: dir, env: "DIR_VAR_1"}}, &TestDirStruct{}); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("err = %v; want %v", err, strconv.ErrSyntax)
+	}
+}
+
+var testOffsetParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if err := RequireParams(params, 1); err != nil {
+		return nil, err
+	}
+	a, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	b, err := strconv.ParseInt(params[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return a + b, nil
+}
+
+func TestRequireParams(t *testing.T) {
+	kwParsers := map[string]Parser{"offset": testOffsetParser}
+	testCasesA := []TestCaseA{
+		{
+			a:   "1",
+			env: "RP_VAR_1",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := struct {
+		Var1 int64 `env_var:"RP_VAR_1" env_parser:"offset" env_params:"2;k=v"`
+	}{}
+	if err := LoadEnvUserParser(&testStruct, kwParsers, nil, nil); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: int64(3)}})
+	err := LoadEnvUserParser(&struct {
+		Var1 int64 `env_var:"RP_VAR_1" env_parser:"offset"`
+	}{}, kwParsers, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "1 positional params required but 0 given") {
+		t.Errorf("err = %v; want arity error", err)
+	}
+	err = LoadEnvUserParser(&struct {
+		Var1 int64 `env_var:"RP_VAR_1" env_parser:"offset" env_params:"1;2"`
+	}{}, kwParsers, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "1 positional params required but 2 given") {
+		t.Errorf("err = %v; want arity error", err)
+	}
+}
+
+func TestPathParser(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCasesA := []TestCaseA{
+		{
+			a:   "~/config/../conf",
+			env: "PATH_VAR_1",
+		},
+		{
+			a:   "$PATH_VAR_BASE/${PATH_VAR_BASE}/x/",
+			env: "PATH_VAR_2",
+		},
+		{
+			a:   "/etc/app",
+			env: "PATH_VAR_BASE",
+		},
+		{
+			a:   "./data//x",
+			env: "PATH_VAR_3",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := struct {
+		Var1 string `env_var:"PATH_VAR_1" env_parser:"path"`
+		Var2 string `env_var:"PATH_VAR_2" env_parser:"path"`
+		Var3 string `env_var:"PATH_VAR_3" env_parser:"path"`
+		Var4 string `env_var:"PATH_VAR_3" env_parser:"path" env_params:"abs=true"`
+	}{}
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: filepath.Join(home, "conf"),
+		},
+		{
+			b:    testStruct.Var2,
+			want: "/etc/app/etc/app/x",
+		},
+		{
+			b:    testStruct.Var3,
+			want: filepath.Join("data", "x"),
+		},
+		{
+			b:    testStruct.Var4,
+			want: filepath.Join(wd, "data", "x"),
+		},
+	}
+	testValues(t, testCasesB)
+	if err := LoadEnv(&struct {
+		Var1 int `env_var:"PATH_VAR_1" env_parser:"path"`
+	}{}); err == nil {
+		t.Error("expected error for int field")
+	}
+}
+
+type TestBoolIntStruct struct {
+	Var1 int   `env_var:"BOOLINT_VAR_1" env_parser:"boolint"`
+	Var2 uint8 `env_var:"BOOLINT_VAR_2" env_parser:"boolint"`
+}
+
+func TestBoolIntParser(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "true",
+			env: "BOOLINT_VAR_1",
+		},
+		{
+			a:   "false",
+			env: "BOOLINT_VAR_2",
+		},
+	}
+	testStruct := TestBoolIntStruct{Var2: 5}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: 1}, {b: testStruct.Var2, want: uint8(0)}})
+	testCasesA[0].a = "yes"
+	if err := loadTestStruct(t, testCasesA, &TestBoolIntStruct{}); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("err = %v; want %v", err, strconv.ErrSyntax)
+	}
+	err := loadTestStruct(t, testCasesA[1:], &struct {
+		Var1 float64 `env_var:"BOOLINT_VAR_2" env_parser:"boolint"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "'float64' provided but integer required") {
+		t.Errorf("err = %v; want kind error", err)
+	}
+}
+
+type TestLinesStruct struct {
+	Var1 []string `env_var:"LINES_VAR_1" env_parser:"lines"`
+	Var2 []int    `env_var:"LINES_VAR_2" env_parser:"lines"`
+}
+
+func TestLinesParser(t *testing.T) {
+	testCases := []struct {
+		a, b string
+	}{
+		{
+			a: "a b\nc\nd",
+			b: "1\n2\n3",
+		},
+		{
+			a: "a b\r\nc\r\nd",
+			b: "1\r\n2\r\n3",
+		},
+		{
+			a: "a b\n\nc\nd\n\n\r\n",
+			b: "1\n2\n3\n\n",
+		},
+	}
+	for _, testCase := range testCases {
+		testStruct := TestLinesStruct{}
+		if err := loadTestStruct(t, []TestCaseA{{a: testCase.a, env: "LINES_VAR_1"}, {a: testCase.b, env: "LINES_VAR_2"}}, &testStruct); err != nil {
+			t.Error(err)
+		}
+		testValues(t, []TestCaseB{{b: testStruct.Var1, want: []string{"a b", "c", "d"}}, {b: testStruct.Var2, want: []int{1, 2, 3}}})
+	}
+	err := loadTestStruct(t, []TestCaseA{{a: "1\n\nx", env: "LINES_VAR_2"}}, &TestLinesStruct{})
+	if !errors.Is(err, strconv.ErrSyntax) || !strings.Contains(err.Error(), "line 3:") {
+		t.Errorf("err = %v; want syntax error in line 3", err)
+	}
+}
+
+type TestRawMessageStruct struct {
+	Var1 json.RawMessage  `env_var:"RAW_VAR_1"`
+	Var2 *json.RawMessage `env_var:"RAW_VAR_1"`
+}
+
+func TestRawMessageParser(t *testing.T) {
+	raw := " {\n  \"a\": [1, 2],\t\"b\": null }\n"
+	testStruct := TestRawMessageStruct{}
+	if err := loadTestStruct(t, []TestCaseA{{a: raw, env: "RAW_VAR_1"}}, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	if testStruct.Var2 == nil {
+		t.Fatal("Var2 not set")
+	}
+	testValues(t, []TestCaseB{{b: string(testStruct.Var1), want: raw}, {b: string(*testStruct.Var2), want: raw}})
+	err := loadTestStruct(t, []TestCaseA{{a: "{\"a\":", env: "RAW_VAR_1"}}, &TestRawMessageStruct{})
+	if err == nil || !strings.Contains(err.Error(), "invalid json") {
+		t.Errorf("err = %v; want invalid json error", err)
+	}
+}
+
+type TestByteSizeStruct struct {
+	Var1 int64  `env_var:"BYTESIZE_VAR_1" env_parser:"bytesize"`
+	Var2 uint64 `env_var:"BYTESIZE_VAR_2" env_parser:"bytesize"`
+	Var3 int    `env_var:"BYTESIZE_VAR_3" env_parser:"bytesize"`
+}
+
+func TestByteSizeParser(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "10MB",
+			env: "BYTESIZE_VAR_1",
+		},
+		{
+			a:   "2 GiB",
+			env: "BYTESIZE_VAR_2",
+		},
+		{
+			a:   "512",
+			env: "BYTESIZE_VAR_3",
+		},
+	}
+	testStruct := TestByteSizeStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: int64(10000000)}, {b: testStruct.Var2, want: uint64(2147483648)}, {b: testStruct.Var3, want: 512}})
+	for _, val := range []string{"10M", "10mb", "10 XB", "1.5GB"} {
+		testCasesA[0].a = val
+		if err := loadTestStruct(t, testCasesA, &TestByteSizeStruct{}); err == nil {
+			t.Errorf("%s: expected error", val)
+		}
+	}
+	testCasesA[0].a = "16EiB"
+	if err := loadTestStruct(t, testCasesA, &TestByteSizeStruct{}); !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("err = %v; want %v", err, strconv.ErrRange)
+	}
+	err := loadTestStruct(t, []TestCaseA{{a: "1KiB", env: "BYTESIZE_VAR_1"}}, &struct {
+		Var1 uint8 `env_var:"BYTESIZE_VAR_1" env_parser:"bytesize"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "value 1KiB overflows uint8") {
+		t.Errorf("err = %v; want overflow error", err)
+	}
+}
+
+type TestPercentStruct struct {
+	Var1 float64 `env_var:"PERCENT_VAR_1" env_parser:"percent"`
+	Var2 float32 `env_var:"PERCENT_VAR_2" env_parser:"percent"`
+	Var3 float64 `env_var:"PERCENT_VAR_3" env_parser:"percent"`
+	Var4 float64 `env_var:"PERCENT_VAR_4" env_parser:"percent"`
+}
+
+func TestPercentParser(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "50%",
+			env: "PERCENT_VAR_1",
+		},
+		{
+			a:   "0%",
+			env: "PERCENT_VAR_2",
+		},
+		{
+			a:   "100%",
+			env: "PERCENT_VAR_3",
+		},
+		{
+			a:   "0.25",
+			env: "PERCENT_VAR_4",
+		},
+	}
+	testStruct := TestPercentStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: 0.5}, {b: testStruct.Var2, want: float32(0)}, {b: testStruct.Var3, want: 1.0}, {b: testStruct.Var4, want: 0.25}})
+	testCasesA[0].a = "abc%"
+	if err := loadTestStruct(t, testCasesA, &TestPercentStruct{}); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("err = %v; want %v", err, strconv.ErrSyntax)
+	}
+	err := loadTestStruct(t, testCasesA[3:], &struct {
+		Var1 float64 `env_var:"PERCENT_VAR_4" env_parser:"percent" env_params:"strict=true"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "missing '%' in '0.25'") {
+		t.Errorf("err = %v; want missing percent error", err)
+	}
+}
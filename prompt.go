@@ -0,0 +1,62 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const promptKw = "prompt"
+
+// PromptFunc displays the prompt and returns the value entered by the user.
+// Secret is set for fields with the secret parameter and indicates that the input should not be echoed.
+type PromptFunc func(prompt string, secret bool) (string, error)
+
+// LinePrompt writes the prompt to w and reads a single line from r.
+// The input is not hidden for secret fields, use a terminal specific PromptFunc for that.
+func LinePrompt(r io.Reader, w io.Writer) PromptFunc {
+	br := bufio.NewReader(r)
+	return func(prompt string, secret bool) (string, error) {
+		if _, err := fmt.Fprint(w, prompt); err != nil {
+			return "", err
+		}
+		line, err := br.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+}
+
+func (l *loader) promptEnv(f *field, prefix string) (name string, val string, ok bool, err error) {
+	if !l.isTagged(f) || l.prompt == nil {
+		return
+	}
+	prompt, k := f.kwParams[promptKw]
+	if !k {
+		return
+	}
+	name = l.envName(f, prefix)
+	if val, err = l.prompt(prompt, isSecret(f.kwParams)); err != nil {
+		return name, "", false, &LoadError{Field: f.st.Name, EnvVar: name, Err: fmt.Errorf("prompt failed: %w", err)}
+	}
+	return name, val, true, nil
+}
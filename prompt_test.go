@@ -0,0 +1,115 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type TestPromptStruct struct {
+	User     string `env_var:"PROMPT_USER" env_params:"prompt=User: "`
+	Password string `env_var:"PROMPT_PASSWORD" env_params:"prompt=Password: ;secret=true"`
+	Port     int    `env_var:"PROMPT_PORT"`
+}
+
+func TestLoadPrompt(t *testing.T) {
+	var out bytes.Buffer
+	var secrets []bool
+	prompt := LinePrompt(strings.NewReader("admin\r\n"+testString), &out)
+	testStruct := TestPromptStruct{}
+	err := loadTestStruct(t, nil, &testStruct, WithPrompt(func(p string, secret bool) (string, error) {
+		secrets = append(secrets, secret)
+		return prompt(p, secret)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct,
+			want: TestPromptStruct{User: "admin", Password: testString},
+		},
+		{
+			b:    out.String(),
+			want: "User: Password: ",
+		},
+		{
+			b:    secrets,
+			want: []bool{false, true},
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestLoadPromptEnvSet(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "PROMPT_USER",
+		},
+	}
+	testStruct := TestPromptStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithPrompt(LinePrompt(strings.NewReader(""), io.Discard))); err == nil {
+		t.Error("missing error")
+	}
+	testStruct = TestPromptStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithPrompt(LinePrompt(strings.NewReader("pw\n"), io.Discard))); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestPromptStruct{User: testString, Password: "pw"}}})
+	testStruct = TestPromptStruct{}
+	if err := loadTestStruct(t, nil, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestPromptStruct{}}})
+}
+
+func TestLoadPromptNoOverwrite(t *testing.T) {
+	var prompts []string
+	testStruct := TestPromptStruct{Password: testString}
+	err := loadTestStruct(t, nil, &testStruct, WithNoOverwrite(), WithPrompt(func(p string, secret bool) (string, error) {
+		prompts = append(prompts, p)
+		return "admin", nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct,
+			want: TestPromptStruct{User: "admin", Password: testString},
+		},
+		{
+			b:    prompts,
+			want: []string{"User: "},
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestLoadPromptError(t *testing.T) {
+	testStruct := TestPromptStruct{}
+	var loadErr *LoadError
+	err := loadTestStruct(t, nil, &testStruct, WithPrompt(LinePrompt(strings.NewReader(""), io.Discard)))
+	if !errors.As(err, &loadErr) || !errors.Is(err, io.EOF) {
+		t.Errorf("err = %v; want %v", err, io.EOF)
+	}
+}
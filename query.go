@@ -0,0 +1,97 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const queryKw = "query"
+const strictKw = "strict"
+
+// queryParser returns a parser for query strings like 'host=h&port=5' that sets the fields of a copy
+// of current. Keys are matched by the env_var tags of the struct fields, their values are parsed like env vars.
+func (l *loader) queryParser(current reflect.Value, envVar string) Parser {
+	return func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+		if t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("'%s' provided but '%s' required", t.Kind(), reflect.Struct)
+		}
+		values, err := url.ParseQuery(val)
+		if err != nil {
+			return nil, err
+		}
+		ptr := reflect.New(t)
+		ptr.Elem().Set(current)
+		known := make(map[string]bool)
+		fields := getFields(t)
+		for x := range fields {
+			sub := &fields[x]
+			if !sub.tagged {
+				continue
+			}
+			known[sub.name] = true
+			if !values.Has(sub.name) {
+				if required, err := l.isRequired(sub.kwParams); err != nil {
+					return nil, &LoadError{Field: sub.st.Name, EnvVar: envVar, Err: err}
+				} else if required {
+					return nil, &LoadError{Field: sub.st.Name, EnvVar: envVar, Err: fmt.Errorf("key '%s' required but not set", sub.name)}
+				}
+				continue
+			}
+			subValue := ptr.Elem().Field(sub.index)
+			if subValue.Kind() == reflect.Ptr {
+				elem := reflect.New(subValue.Type().Elem())
+				if !subValue.IsNil() {
+					elem.Elem().Set(subValue.Elem())
+				}
+				subValue.Set(elem)
+				subValue = elem.Elem()
+			}
+			p, _, k := l.getParser(sub.parserKw, subValue.Type())
+			if !k {
+				return nil, &LoadError{Field: sub.st.Name, EnvVar: envVar, Err: fmt.Errorf("no parser for '%s'", subValue.Type())}
+			}
+			itf, err := l.parse(p, sub.st, envVar, subValue.Type(), values.Get(sub.name), sub.params, sub.kwParams)
+			if err != nil {
+				return nil, err
+			}
+			itfValue := reflect.Indirect(reflect.ValueOf(itf))
+			if itfValue.Type() != subValue.Type() && itfValue.Type().ConvertibleTo(subValue.Type()) {
+				itfValue = itfValue.Convert(subValue.Type())
+			}
+			subValue.Set(itfValue)
+		}
+		if strict, _ := strconv.ParseBool(kwParams[strictKw]); strict {
+			var unknown []string
+			for key := range values {
+				if !known[key] {
+					unknown = append(unknown, key)
+				}
+			}
+			if len(unknown) > 0 {
+				sort.Strings(unknown)
+				return nil, fmt.Errorf("unknown keys '%s'", strings.Join(unknown, "', '"))
+			}
+		}
+		return ptr.Interface(), nil
+	}
+}
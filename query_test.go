@@ -0,0 +1,147 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"strings"
+	"testing"
+)
+
+type TestQuerySubStruct struct {
+	Host string `env_var:"host" env_params:"required=true"`
+	Port *int   `env_var:"port"`
+	SSL  bool   `env_var:"ssl"`
+}
+
+type TestQueryCondStruct struct {
+	Host string `env_var:"host"`
+	User string `env_var:"user" env_params:"required_if=QUERY_MODE=auth"`
+}
+
+type TestQueryStruct struct {
+	Var1 TestQuerySubStruct  `env_var:"QUERY_VAR_1" env_parser:"query"`
+	Var2 *TestQuerySubStruct `env_var:"QUERY_VAR_2" env_parser:"query" env_params:"strict=true"`
+}
+
+func TestLoadQuery(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "host=h&port=5&ssl=true",
+			env: "QUERY_VAR_1",
+		},
+		{
+			a:   "host=a%26b%3Dc+d&ssl=false",
+			env: "QUERY_VAR_2",
+		},
+	}
+	testStruct := TestQueryStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	if testStruct.Var1.Port == nil || testStruct.Var2 == nil {
+		t.Fatalf("nil pointer in %+v", testStruct)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1.Host,
+			want: "h",
+		},
+		{
+			b:    *testStruct.Var1.Port,
+			want: 5,
+		},
+		{
+			b:    testStruct.Var1.SSL,
+			want: true,
+		},
+		{
+			b:    *testStruct.Var2,
+			want: TestQuerySubStruct{Host: "a&b=c d"},
+		},
+	}
+	testValues(t, testCasesB)
+	testCasesA[0].a = "host=h&hots=x"
+	if err := loadTestStruct(t, testCasesA, &TestQueryStruct{}); err != nil {
+		t.Error(err)
+	}
+	testCasesA[1].a = "host=h&hots=x&prot=1"
+	err := loadTestStruct(t, testCasesA, &TestQueryStruct{})
+	if err == nil || !strings.Contains(err.Error(), "unknown keys 'hots', 'prot'") {
+		t.Errorf("err = %v; want unknown keys error", err)
+	}
+	testCasesA[0].a = "port=1"
+	err = loadTestStruct(t, testCasesA, &TestQueryStruct{})
+	if err == nil || !strings.Contains(err.Error(), "key 'host' required but not set") {
+		t.Errorf("err = %v; want required error", err)
+	}
+	testCasesA[0].a = "host=h&port=x"
+	err = loadTestStruct(t, testCasesA, &TestQueryStruct{})
+	if le, ok := err.(*LoadError); !ok || le.Field != "Port" || le.EnvVar != "QUERY_VAR_1" {
+		t.Errorf("err = %v; want LoadError for Port", err)
+	}
+}
+
+func TestLoadQueryOptions(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "host=new&ssl=true",
+			env: "QUERY_VAR_1",
+		},
+		{
+			a:   "null",
+			env: "QUERY_VAR_2",
+		},
+	}
+	testStruct := TestQueryStruct{Var1: TestQuerySubStruct{Host: "old"}, Var2: &TestQuerySubStruct{Host: "old"}}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithNoOverwrite()); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: TestQuerySubStruct{Host: "old"}}})
+	testStruct = TestQueryStruct{Var2: &TestQuerySubStruct{Host: "old"}}
+	if err := loadTestStruct(t, testCasesA, &testStruct, WithNullSentinel("null")); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestQueryStruct{Var1: TestQuerySubStruct{Host: "new", SSL: true}}}})
+	var b strings.Builder
+	if err := setEnv(testCasesA[:1]); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA[:1])
+	if err := LoadEnvVerbose(&TestQueryStruct{}, &b); err != nil {
+		t.Fatal(err)
+	}
+	if line := "Var1: env var 'QUERY_VAR_1' set to 'host=new&ssl=true', parsed by query parser to '{new <nil> true}'\n"; !strings.Contains(b.String(), line) {
+		t.Errorf("output missing %q:\n%s", line, b.String())
+	}
+	condStruct := struct {
+		Var1 TestQueryCondStruct `env_var:"QUERY_VAR_3" env_parser:"query"`
+	}{}
+	testCasesA = []TestCaseA{
+		{
+			a:   "host=h",
+			env: "QUERY_VAR_3",
+		},
+	}
+	if err := loadTestStruct(t, testCasesA, &condStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesA = append(testCasesA, TestCaseA{a: "auth", env: "QUERY_MODE"})
+	err := loadTestStruct(t, testCasesA, &condStruct)
+	if le, ok := err.(*LoadError); !ok || le.Field != "User" || !strings.Contains(err.Error(), "key 'user' required but not set") {
+		t.Errorf("err = %v; want required error for User", err)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SemVer is a semantic version of the form MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD].
+type SemVer struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string
+	Build      string
+}
+
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// ParseSemVer parses a semantic version, e.g. '1.2.3' or '1.2.3-rc1'.
+func ParseSemVer(s string) (SemVer, error) {
+	var v SemVer
+	val := s
+	if i := strings.Index(val, "+"); i >= 0 {
+		v.Build = val[i+1:]
+		val = val[:i]
+		if v.Build == "" {
+			return SemVer{}, fmt.Errorf("invalid semantic version '%s'", s)
+		}
+	}
+	if i := strings.Index(val, "-"); i >= 0 {
+		v.PreRelease = val[i+1:]
+		val = val[:i]
+		if v.PreRelease == "" {
+			return SemVer{}, fmt.Errorf("invalid semantic version '%s'", s)
+		}
+	}
+	parts := strings.Split(val, ".")
+	if len(parts) != 3 {
+		return SemVer{}, fmt.Errorf("invalid semantic version '%s'", s)
+	}
+	var nums [3]int
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 31)
+		if err != nil {
+			return SemVer{}, fmt.Errorf("invalid semantic version '%s': %w", s, err)
+		}
+		nums[i] = int(n)
+	}
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+	return v, nil
+}
+
+var semVerParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	if !reflect.TypeOf(SemVer{}).ConvertibleTo(t) {
+		return nil, fmt.Errorf("'%s' provided but '%s' required", t, reflect.TypeOf(SemVer{}))
+	}
+	return ParseSemVer(val)
+}
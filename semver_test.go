@@ -0,0 +1,80 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"testing"
+)
+
+type TestSemVerAlias SemVer
+
+type TestSemVerStruct struct {
+	Var1 SemVer          `env_var:"SEMVER_VAR_1"`
+	Var2 *SemVer         `env_var:"SEMVER_VAR_1"`
+	Var3 TestSemVerAlias `env_var:"SEMVER_VAR_1" env_parser:"semver"`
+}
+
+func TestLoadSemVer(t *testing.T) {
+	testCases := []struct {
+		a    string
+		want SemVer
+	}{
+		{
+			a:    "1.2.3",
+			want: SemVer{Major: 1, Minor: 2, Patch: 3},
+		},
+		{
+			a:    "1.2.3-rc1",
+			want: SemVer{Major: 1, Minor: 2, Patch: 3, PreRelease: "rc1"},
+		},
+		{
+			a:    "0.10.0-beta.2+exp.sha",
+			want: SemVer{Minor: 10, PreRelease: "beta.2", Build: "exp.sha"},
+		},
+	}
+	for _, testCase := range testCases {
+		testStruct := TestSemVerStruct{}
+		if err := loadTestStruct(t, []TestCaseA{{a: testCase.a, env: "SEMVER_VAR_1"}}, &testStruct); err != nil {
+			t.Fatal(err)
+		}
+		testCasesB := []TestCaseB{
+			{
+				b:    testStruct.Var1,
+				want: testCase.want,
+			},
+			{
+				b:    *testStruct.Var2,
+				want: testCase.want,
+			},
+			{
+				b:    testStruct.Var3,
+				want: TestSemVerAlias(testCase.want),
+			},
+			{
+				b:    testStruct.Var1.String(),
+				want: testCase.a,
+			},
+		}
+		testValues(t, testCasesB)
+	}
+	for _, val := range []string{"not.a.version", "1.2", "1.2.3.4", "1.-2.3", "1.2.3-", "1.2.3+"} {
+		testStruct := TestSemVerStruct{}
+		if err := loadTestStruct(t, []TestCaseA{{a: val, env: "SEMVER_VAR_1"}}, &testStruct); err == nil {
+			t.Errorf("missing error for %s", val)
+		}
+	}
+}
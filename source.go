@@ -0,0 +1,65 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"sync"
+	"time"
+)
+
+// Source provides values for env var names, e.g. from a secret store.
+type Source interface {
+	Lookup(name string) (string, bool)
+}
+
+// SourceFunc adapts a function to the Source interface.
+type SourceFunc func(name string) (string, bool)
+
+// Lookup calls f(name).
+func (f SourceFunc) Lookup(name string) (string, bool) {
+	return f(name)
+}
+
+type cacheEntry struct {
+	val     string
+	ok      bool
+	expires time.Time
+}
+
+type cachingSource struct {
+	source Source
+	ttl    time.Duration
+	mu     sync.Mutex
+	cache  map[string]cacheEntry
+}
+
+// CachingSource wraps s and caches looked up values, including missing ones, for the given ttl.
+func CachingSource(s Source, ttl time.Duration) Source {
+	return &cachingSource{source: s, ttl: ttl, cache: make(map[string]cacheEntry)}
+}
+
+func (c *cachingSource) Lookup(name string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if e, ok := c.cache[name]; ok && now.Before(e.expires) {
+		return e.val, e.ok
+	}
+	val, ok := c.source.Lookup(name)
+	c.cache[name] = cacheEntry{val: val, ok: ok, expires: now.Add(c.ttl)}
+	return val, ok
+}
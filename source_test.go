@@ -0,0 +1,118 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"testing"
+	"time"
+)
+
+type testBackend struct {
+	values map[string]string
+	calls  map[string]int
+}
+
+func (b *testBackend) Lookup(name string) (string, bool) {
+	b.calls[name]++
+	val, ok := b.values[name]
+	return val, ok
+}
+
+func TestWithSource(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "env",
+			env: "SOURCE_VAR_1",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	backend := &testBackend{values: map[string]string{"SOURCE_VAR_1": "a", "SOURCE_VAR_2": "b"}, calls: make(map[string]int)}
+	other := SourceFunc(func(name string) (string, bool) {
+		return "c", name == "SOURCE_VAR_3"
+	})
+	testStruct := struct {
+		Var1 string `env_var:"SOURCE_VAR_1"`
+		Var2 string `env_var:"SOURCE_VAR_2"`
+		Var3 string `env_var:"SOURCE_VAR_3"`
+		Var4 string `env_var:"SOURCE_VAR_4"`
+	}{}
+	if err := LoadEnv(&testStruct, WithSource(backend), WithSource(other)); err != nil {
+		t.Fatal(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: "env",
+		},
+		{
+			b:    testStruct.Var2,
+			want: "b",
+		},
+		{
+			b:    testStruct.Var3,
+			want: "c",
+		},
+		{
+			b:    testStruct.Var4,
+			want: "",
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestCachingSource(t *testing.T) {
+	backend := &testBackend{values: map[string]string{"SOURCE_VAR_1": "a"}, calls: make(map[string]int)}
+	testStruct := struct {
+		Var1 string `env_var:"SOURCE_VAR_1"`
+		Var2 string `env_var:"SOURCE_VAR_1"`
+		Var3 string `env_var:"SOURCE_VAR_2"`
+		Var4 string `env_var:"SOURCE_VAR_2"`
+	}{}
+	source := CachingSource(backend, 50*time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if err := LoadEnv(&testStruct, WithSource(source)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: "a",
+		},
+		{
+			b:    testStruct.Var2,
+			want: "a",
+		},
+		{
+			b:    backend.calls["SOURCE_VAR_1"],
+			want: 1,
+		},
+		{
+			b:    backend.calls["SOURCE_VAR_2"],
+			want: 1,
+		},
+	}
+	testValues(t, testCasesB)
+	time.Sleep(60 * time.Millisecond)
+	if err := LoadEnv(&testStruct, WithSource(source)); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: backend.calls["SOURCE_VAR_1"], want: 2}})
+}
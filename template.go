@@ -0,0 +1,79 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+const tmplKw = "tmpl"
+
+// templateFields returns the names of the fields referenced in the template, e.g. HOST for {{.HOST}}.
+func templateFields(node parse.Node, names []string) []string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			for _, c := range n.Nodes {
+				names = templateFields(c, names)
+			}
+		}
+	case *parse.ActionNode:
+		names = templateFields(n.Pipe, names)
+	case *parse.PipeNode:
+		if n != nil {
+			for _, c := range n.Cmds {
+				names = templateFields(c, names)
+			}
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			names = templateFields(a, names)
+		}
+	case *parse.FieldNode:
+		names = append(names, n.Ident[0])
+	case *parse.IfNode:
+		names = templateFields(n.List, templateFields(n.ElseList, templateFields(n.Pipe, names)))
+	case *parse.WithNode:
+		names = templateFields(n.List, templateFields(n.ElseList, templateFields(n.Pipe, names)))
+	}
+	return names
+}
+
+// renderTemplate renders the template of the tmpl parameter with the referenced env vars. envVar is
+// empty for fields without env var, errors for missing env vars name the referenced one.
+func (l *loader) renderTemplate(f *field, envVar, text string) (string, error) {
+	tmpl, err := template.New(f.st.Name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", &LoadError{Field: f.st.Name, EnvVar: envVar, Err: err}
+	}
+	data := make(map[string]string)
+	for _, name := range templateFields(tmpl.Root, nil) {
+		val, ok := l.lookup(name)
+		if !ok {
+			return "", &LoadError{Field: f.st.Name, EnvVar: name, Err: fmt.Errorf("env var '%s' referenced by template not set", name)}
+		}
+		data[name] = val
+	}
+	var b strings.Builder
+	if err = tmpl.Execute(&b, data); err != nil {
+		return "", &LoadError{Field: f.st.Name, EnvVar: envVar, Err: err}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,62 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"strings"
+	"testing"
+)
+
+type TestTemplateStruct struct {
+	Addr  string `env_params:"tmpl={{.TMPL_HOST}}:{{.TMPL_PORT}}"`
+	URL   string `env_var:"TMPL_URL" env_params:"tmpl=http://{{.TMPL_HOST}}{{if .TMPL_PORT}}:{{.TMPL_PORT}}{{end}}/"`
+	Port  int    `env_params:"tmpl={{.TMPL_PORT}}"`
+	Other string
+}
+
+func TestLoadTemplate(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "localhost",
+			env: "TMPL_HOST",
+		},
+		{
+			a:   "8080",
+			env: "TMPL_PORT",
+		},
+	}
+	testStruct := TestTemplateStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct, want: TestTemplateStruct{Addr: "localhost:8080", URL: "http://localhost:8080/", Port: 8080}}})
+	testStruct = TestTemplateStruct{}
+	if err := loadTestStruct(t, append(testCasesA, TestCaseA{a: "http://other/", env: "TMPL_URL"}), &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.URL, want: "http://other/"}})
+	err := loadTestStruct(t, testCasesA[:1], &TestTemplateStruct{})
+	if le, ok := err.(*LoadError); !ok || le.EnvVar != "TMPL_PORT" || !strings.Contains(err.Error(), "env var 'TMPL_PORT' referenced by template not set") {
+		t.Errorf("err = %v; want missing env var error", err)
+	}
+	err = loadTestStruct(t, testCasesA, &struct {
+		Addr string `env_params:"tmpl={{.TMPL_HOST"`
+	}{})
+	if le, ok := err.(*LoadError); !ok || le.Field != "Addr" || le.EnvVar != "" {
+		t.Errorf("err = %v; want template error", err)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const layoutKw = "layout"
+const unixKw = "unix"
+const maxKw = "max"
+const zoneKw = "zone"
+
+// durationSyntax matches values accepted by time.ParseDuration apart from overflows.
+var durationSyntax = regexp.MustCompile(`^[-+]?(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+$`)
+
+// timeParser interprets values without zone information in UTC or the location of the zone parameter.
+// Parsing doesn't depend on the locale of the host, month and weekday names are always English.
+var timeParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	var loc *time.Location
+	if z, ok := kwParams[zoneKw]; ok {
+		var err error
+		if loc, err = time.LoadLocation(z); err != nil {
+			return nil, fmt.Errorf("invalid %s '%s': %w", zoneKw, z, err)
+		}
+	}
+	if unit, ok := kwParams[unixKw]; ok {
+		i, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		var ts time.Time
+		switch unit {
+		case "s":
+			ts = time.Unix(i, 0)
+		case "ms":
+			ts = time.UnixMilli(i)
+		default:
+			return nil, fmt.Errorf("unknown unix time unit '%s'", unit)
+		}
+		if loc != nil {
+			ts = ts.In(loc)
+		}
+		return ts, nil
+	}
+	layout := time.RFC3339
+	if l, ok := kwParams[layoutKw]; ok && l != "" {
+		layout = l
+	}
+	if loc != nil {
+		return time.ParseInLocation(layout, val, loc)
+	}
+	return time.Parse(layout, val)
+}
+
+// durationParser accepts Go duration strings like 1h30m and, for backwards compatibility, plain nanoseconds.
+var durationParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	var d time.Duration
+	i, err := strconv.ParseInt(val, 10, 64)
+	switch {
+	case err == nil:
+		d = time.Duration(i)
+	case errors.Is(err, strconv.ErrRange):
+		return nil, err
+	default:
+		if d, err = time.ParseDuration(val); err != nil {
+			if durationSyntax.MatchString(val) {
+				return nil, fmt.Errorf("%w: %s", strconv.ErrRange, err)
+			}
+			return nil, err
+		}
+	}
+	if m, ok := kwParams[maxKw]; ok {
+		max, err := time.ParseDuration(m)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s '%s': %w", maxKw, m, err)
+		}
+		if d > max {
+			return nil, fmt.Errorf("%s exceeds the maximum of %s", d, max)
+		}
+	}
+	return d, nil
+}
+
+var locationParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	return time.LoadLocation(val)
+}
+
+// parseTimeEnum resolves a case-insensitive name or an integer within [min, max] via the String method of the enum.
+func parseTimeEnum(val string, min, max int, name func(i int) string) (int, error) {
+	if i, err := strconv.Atoi(val); err == nil {
+		if i < min || i > max {
+			return 0, fmt.Errorf("%d not in range %d-%d: %w", i, min, max, strconv.ErrRange)
+		}
+		return i, nil
+	}
+	for i := min; i <= max; i++ {
+		if strings.EqualFold(val, name(i)) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown name '%s'", val)
+}
+
+var weekdayParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	i, err := parseTimeEnum(val, int(time.Sunday), int(time.Saturday), func(i int) string { return time.Weekday(i).String() })
+	if err != nil {
+		return nil, err
+	}
+	return time.Weekday(i), nil
+}
+
+var monthParser Parser = func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	i, err := parseTimeEnum(val, int(time.January), int(time.December), func(i int) string { return time.Month(i).String() })
+	if err != nil {
+		return nil, err
+	}
+	return time.Month(i), nil
+}
@@ -0,0 +1,367 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+type TestTimeStruct struct {
+	Var1       time.Time  `env_var:"TIME_VAR_1"`
+	Var1NilPtr *time.Time `env_var:"TIME_VAR_1"`
+	Var2       time.Time  `env_var:"TIME_VAR_2" env_params:"layout=2006-01-02"`
+	Var3       time.Time  `env_var:"TIME_VAR_3" env_params:"unix=s"`
+	Var4       time.Time  `env_var:"TIME_VAR_4" env_params:"unix=ms"`
+}
+
+func TestLoadTime(t *testing.T) {
+	testTime := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	testCasesA := []TestCaseA{
+		{
+			a:   testTime.Format(time.RFC3339),
+			env: "TIME_VAR_1",
+		},
+		{
+			a:   testTime.Format("2006-01-02"),
+			env: "TIME_VAR_2",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := TestTimeStruct{}
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1,
+			want: testTime,
+		},
+		{
+			b:    *testStruct.Var1NilPtr,
+			want: testTime,
+		},
+		{
+			b:    testStruct.Var2,
+			want: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	testValues(t, testCasesB)
+}
+
+func TestLoadTimeUnix(t *testing.T) {
+	testTime := time.Date(2022, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	testCasesA := []TestCaseA{
+		{
+			a:   "1641092645",
+			env: "TIME_VAR_3",
+		},
+		{
+			a:   "1641092645006",
+			env: "TIME_VAR_4",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	testStruct := TestTimeStruct{}
+	if err := LoadEnv(&testStruct); err != nil {
+		t.Error(err)
+	}
+	if !testStruct.Var3.Equal(testTime.Truncate(time.Second)) {
+		t.Errorf("b = %s; want %s", testStruct.Var3, testTime.Truncate(time.Second))
+	}
+	if !testStruct.Var4.Equal(testTime) {
+		t.Errorf("b = %s; want %s", testStruct.Var4, testTime)
+	}
+	if err := unsetEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	testCasesA = []TestCaseA{
+		{
+			a:   "1641092645.5",
+			env: "TIME_VAR_3",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	if err := LoadEnv(&testStruct); err == nil {
+		t.Error("missing error")
+	}
+}
+
+type TestLocationStruct struct {
+	Var1 *time.Location `env_var:"LOC_VAR_1"`
+	Var2 *time.Location `env_var:"LOC_VAR_2"`
+}
+
+func TestLoadLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		panic(err)
+	}
+	testCasesA := []TestCaseA{
+		{
+			a:   "Europe/Berlin",
+			env: "LOC_VAR_1",
+		},
+		{
+			a:   "UTC",
+			env: "LOC_VAR_2",
+		},
+	}
+	testStruct := TestLocationStruct{Var2: time.Local}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Error(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1.String(),
+			want: berlin.String(),
+		},
+		{
+			b:    testStruct.Var2,
+			want: time.UTC,
+		},
+		{
+			b:    time.Local.String(),
+			want: "Local",
+		},
+	}
+	testValues(t, testCasesB)
+	testCasesA = []TestCaseA{
+		{
+			a:   "Not/AZone",
+			env: "LOC_VAR_1",
+		},
+	}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err == nil {
+		t.Error("missing error")
+	}
+}
+
+type TestTimeEnumStruct struct {
+	Var1 time.Weekday  `env_var:"WEEKDAY_VAR_1"`
+	Var2 time.Month    `env_var:"MONTH_VAR_1"`
+	Var3 *time.Weekday `env_var:"WEEKDAY_VAR_1"`
+}
+
+func TestLoadTimeEnums(t *testing.T) {
+	testCases := []struct {
+		weekday, month string
+		want           TestTimeEnumStruct
+	}{
+		{
+			weekday: "monday",
+			month:   "JANUARY",
+			want:    TestTimeEnumStruct{Var1: time.Monday, Var2: time.January},
+		},
+		{
+			weekday: "0",
+			month:   "12",
+			want:    TestTimeEnumStruct{Var1: time.Sunday, Var2: time.December},
+		},
+	}
+	for _, testCase := range testCases {
+		testStruct := TestTimeEnumStruct{}
+		if err := loadTestStruct(t, []TestCaseA{{a: testCase.weekday, env: "WEEKDAY_VAR_1"}, {a: testCase.month, env: "MONTH_VAR_1"}}, &testStruct); err != nil {
+			t.Fatal(err)
+		}
+		testCasesB := []TestCaseB{
+			{
+				b:    testStruct.Var1,
+				want: testCase.want.Var1,
+			},
+			{
+				b:    testStruct.Var2,
+				want: testCase.want.Var2,
+			},
+			{
+				b:    *testStruct.Var3,
+				want: testCase.want.Var1,
+			},
+		}
+		testValues(t, testCasesB)
+	}
+	for _, testCaseA := range []TestCaseA{{a: "Moonday", env: "WEEKDAY_VAR_1"}, {a: "7", env: "WEEKDAY_VAR_1"}, {a: "0", env: "MONTH_VAR_1"}, {a: "Jan", env: "MONTH_VAR_1"}} {
+		if err := loadTestStruct(t, []TestCaseA{testCaseA}, &TestTimeEnumStruct{}); err == nil {
+			t.Errorf("missing error for %s", testCaseA.a)
+		}
+	}
+}
+
+type TestDurationStruct struct {
+	Var1 time.Duration  `env_var:"DUR_VAR_1"`
+	Var2 *time.Duration `env_var:"DUR_VAR_2" env_params:"max=24h"`
+}
+
+func TestLoadDuration(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "1h30m",
+			env: "DUR_VAR_1",
+		},
+		{
+			a:   "24h",
+			env: "DUR_VAR_2",
+		},
+	}
+	testStruct := TestDurationStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: 90 * time.Minute}, {b: *testStruct.Var2, want: 24 * time.Hour}})
+	testCasesA[0].a = "5"
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: 5 * time.Nanosecond}})
+	for _, val := range []string{"9999999h", "99999999999999999999"} {
+		err := loadTestStruct(t, []TestCaseA{{a: val, env: "DUR_VAR_1"}}, &TestDurationStruct{})
+		if !errors.Is(err, strconv.ErrRange) || !strings.Contains(err.Error(), "value "+val+" overflows time.Duration") {
+			t.Errorf("err = %v; want overflow error", err)
+		}
+	}
+	if err := loadTestStruct(t, []TestCaseA{{a: "1x", env: "DUR_VAR_1"}}, &TestDurationStruct{}); err == nil || errors.Is(err, strconv.ErrRange) {
+		t.Errorf("err = %v; want syntax error", err)
+	}
+	err := loadTestStruct(t, []TestCaseA{{a: "25h", env: "DUR_VAR_2"}}, &TestDurationStruct{})
+	if err == nil || !strings.Contains(err.Error(), "25h0m0s exceeds the maximum of 24h0m0s") {
+		t.Errorf("err = %v; want max error", err)
+	}
+}
+
+type TestTimeZoneStruct struct {
+	Var1 time.Time `env_var:"TIME_ZONE_VAR_1" env_params:"layout=2006-01-02 15:04;zone=Europe/Berlin"`
+	Var2 time.Time `env_var:"TIME_ZONE_VAR_2" env_params:"layout=2006-01-02 15:04;zone=Europe/Berlin"`
+	Var3 time.Time `env_var:"TIME_ZONE_VAR_1" env_params:"layout=2006-01-02 15:04"`
+	Var4 time.Time `env_var:"TIME_ZONE_VAR_3" env_params:"zone=Europe/Berlin"`
+	Var5 time.Time `env_var:"TIME_ZONE_VAR_4" env_params:"unix=s;zone=Europe/Berlin"`
+}
+
+func TestTimeZone(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "2024-07-01 12:00",
+			env: "TIME_ZONE_VAR_1",
+		},
+		{
+			a:   "2024-01-15 12:00",
+			env: "TIME_ZONE_VAR_2",
+		},
+		{
+			a:   "2024-07-01T12:00:00Z",
+			env: "TIME_ZONE_VAR_3",
+		},
+		{
+			a:   "0",
+			env: "TIME_ZONE_VAR_4",
+		},
+	}
+	testStruct := TestTimeZoneStruct{}
+	if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+		t.Fatal(err)
+	}
+	testCasesB := []TestCaseB{
+		{
+			b:    testStruct.Var1.UTC(),
+			want: time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			b:    testStruct.Var2.UTC(),
+			want: time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			b:    testStruct.Var3,
+			want: time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			b:    testStruct.Var4.UTC(),
+			want: time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			b:    testStruct.Var5.Location().String(),
+			want: "Europe/Berlin",
+		},
+		{
+			b:    testStruct.Var1.Location().String(),
+			want: "Europe/Berlin",
+		},
+	}
+	testValues(t, testCasesB)
+	err := loadTestStruct(t, testCasesA[:1], &struct {
+		Var1 time.Time `env_var:"TIME_ZONE_VAR_1" env_params:"layout=2006-01-02 15:04;zone=Mars/Olympus"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "invalid zone 'Mars/Olympus'") {
+		t.Errorf("err = %v; want invalid zone error", err)
+	}
+}
+
+type TestTimeLocaleStruct struct {
+	Var1 time.Time    `env_var:"TIME_LOCALE_VAR_1" env_params:"layout=Monday, 02 January 2006 15:04 MST"`
+	Var2 time.Month   `env_var:"TIME_LOCALE_VAR_2"`
+	Var3 time.Weekday `env_var:"TIME_LOCALE_VAR_3"`
+	Var4 time.Time    `env_var:"TIME_LOCALE_VAR_4" env_params:"layout=Jan 2 2006"`
+}
+
+func TestTimeLocaleIndependent(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   "Monday, 04 March 2024 09:30 UTC",
+			env: "TIME_LOCALE_VAR_1",
+		},
+		{
+			a:   "March",
+			env: "TIME_LOCALE_VAR_2",
+		},
+		{
+			a:   "Monday",
+			env: "TIME_LOCALE_VAR_3",
+		},
+		{
+			a:   "Dec 24 2024",
+			env: "TIME_LOCALE_VAR_4",
+		},
+	}
+	want := TestTimeLocaleStruct{
+		Var1: time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC),
+		Var2: time.March,
+		Var3: time.Monday,
+		Var4: time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC),
+	}
+	for _, locale := range []string{"C", "en_US.UTF-8", "de_DE.UTF-8", "fr_FR.UTF-8", "ja_JP.UTF-8", "tr_TR.UTF-8"} {
+		t.Setenv("LANG", locale)
+		t.Setenv("LC_ALL", locale)
+		t.Setenv("LC_TIME", locale)
+		testStruct := TestTimeLocaleStruct{}
+		if err := loadTestStruct(t, testCasesA, &testStruct); err != nil {
+			t.Fatalf("%s: %s", locale, err)
+		}
+		if !testStruct.Var1.Equal(want.Var1) || testStruct.Var2 != want.Var2 || testStruct.Var3 != want.Var3 || !testStruct.Var4.Equal(want.Var4) {
+			t.Errorf("%s: got %+v; want %+v", locale, testStruct, want)
+		}
+	}
+}
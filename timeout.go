@@ -0,0 +1,64 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const timeoutKw = "timeout"
+
+type parseResult struct {
+	itf interface{}
+	err error
+}
+
+// callParser calls the parser, with a deadline if the timeout parameter is set. A parser
+// exceeding the deadline keeps running in the background, its result is discarded.
+func (l *loader) callParser(p Parser, t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+	d, ok := kwParams[timeoutKw]
+	if !ok {
+		return p(t, val, params, kwParams)
+	}
+	timeout, err := time.ParseDuration(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout '%s': %w", d, err)
+	}
+	ch := make(chan parseResult, 1)
+	go func() {
+		if l.recover {
+			defer func() {
+				if r := recover(); r != nil {
+					ch <- parseResult{err: fmt.Errorf("parser panicked: %v", r)}
+				}
+			}()
+		}
+		itf, err := p(t, val, params, kwParams)
+		ch <- parseResult{itf: itf, err: err}
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case res := <-ch:
+		return res.itf, res.err
+	case <-timer.C:
+		return nil, fmt.Errorf("parser timed out after %s: %w", timeout, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParserTimeout(t *testing.T) {
+	kwParsers := map[string]Parser{
+		"slow": func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+			time.Sleep(200 * time.Millisecond)
+			return val, nil
+		},
+		"fast": func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+			return val, nil
+		},
+		"panic": func(t reflect.Type, val string, params []string, kwParams map[string]string) (interface{}, error) {
+			panic("boom")
+		},
+	}
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "TIMEOUT_VAR_1",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer unsetEnv(testCasesA)
+	testStruct := struct {
+		Var1 string `env_var:"TIMEOUT_VAR_1" env_parser:"fast" env_params:"timeout=1s"`
+	}{}
+	if err := LoadEnvUserParser(&testStruct, kwParsers, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Var1, want: testString}})
+	start := time.Now()
+	err := LoadEnvUserParser(&struct {
+		Var1 string `env_var:"TIMEOUT_VAR_1" env_parser:"slow" env_params:"timeout=20ms"`
+	}{}, kwParsers, nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "parser timed out after 20ms") {
+		t.Errorf("err = %v; want %v", err, context.DeadlineExceeded)
+	}
+	if d := time.Since(start); d > 150*time.Millisecond {
+		t.Errorf("load took %s; want less than 150ms", d)
+	}
+	err = LoadEnvUserParser(&struct {
+		Var1 string `env_var:"TIMEOUT_VAR_1" env_parser:"panic" env_params:"timeout=1s"`
+	}{}, kwParsers, nil, nil, WithRecover(true))
+	if err == nil || !strings.Contains(err.Error(), "parser panicked: boom") {
+		t.Errorf("err = %v; want panic error", err)
+	}
+	err = LoadEnvUserParser(&struct {
+		Var1 string `env_var:"TIMEOUT_VAR_1" env_parser:"fast" env_params:"timeout=soon"`
+	}{}, kwParsers, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid timeout 'soon'") {
+		t.Errorf("err = %v; want invalid timeout error", err)
+	}
+}
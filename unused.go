@@ -0,0 +1,44 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"sort"
+	"strings"
+)
+
+// unused returns the sorted names of env vars with the given prefix that were not looked up during loading.
+func (l *loader) unused(prefix string) []string {
+	var names []string
+	for name := range l.environ() {
+		if strings.HasPrefix(name, prefix) && !l.used[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UnusedEnvVars returns the env vars with the given prefix that are not read by any field of itf, e.g. to detect typos.
+// The struct is left untouched like with ValidateEnv.
+func UnusedEnvVars(itf interface{}, prefix string, opts ...Option) ([]string, error) {
+	l := &loader{dryRun: true, used: make(map[string]bool)}
+	if err := load(itf, l, opts); err != nil {
+		return nil, err
+	}
+	return l.unused(prefix), nil
+}
@@ -0,0 +1,79 @@
+/*
+   Copyright 2022 Yann Dumont
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package envldr
+
+import (
+	"testing"
+)
+
+type TestUnusedStruct struct {
+	Host  string            `env_var:"APP_HOST"`
+	Port  int               `env_var:"APP_PORT"`
+	Items []TestSubStruct   `env_var:"APP_ITEM" env_parser:"recurse"`
+	Extra map[string]string `env_parser:"allenv" env_params:"prefix=APP_LABEL_"`
+}
+
+func TestUnusedEnvVars(t *testing.T) {
+	testCasesA := []TestCaseA{
+		{
+			a:   testString,
+			env: "APP_HOST",
+		},
+		{
+			a:   testString,
+			env: "APP_ITEM_0_SUB_VAR",
+		},
+		{
+			a:   testString,
+			env: "APP_LABEL_A",
+		},
+		{
+			a:   testString,
+			env: "APP_EXTRA",
+		},
+		{
+			a:   testString,
+			env: "APP_ITEM_2_SUB_VAR",
+		},
+	}
+	if err := setEnv(testCasesA); err != nil {
+		panic(err)
+	}
+	defer func() {
+		if err := unsetEnv(testCasesA); err != nil {
+			panic(err)
+		}
+	}()
+	testStruct := TestUnusedStruct{}
+	unused, err := UnusedEnvVars(&testStruct, "APP_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testValues(t, []TestCaseB{{b: unused, want: []string{"APP_EXTRA", "APP_ITEM_2_SUB_VAR"}}, {b: testStruct, want: TestUnusedStruct{}}})
+	err = LoadEnv(&testStruct, WithStrictPrefix("APP_"))
+	if err == nil || err.Error() != "unknown env vars with prefix 'APP_': APP_EXTRA, APP_ITEM_2_SUB_VAR" {
+		t.Errorf("err = %v; want unknown env vars error", err)
+	}
+	if err = unsetEnv(testCasesA[3:]); err != nil {
+		panic(err)
+	}
+	testStruct = TestUnusedStruct{}
+	if err = LoadEnv(&testStruct, WithStrictPrefix("APP_")); err != nil {
+		t.Error(err)
+	}
+	testValues(t, []TestCaseB{{b: testStruct.Host, want: testString}, {b: len(testStruct.Items), want: 1}})
+}